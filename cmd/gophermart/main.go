@@ -2,17 +2,50 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/a2sh3r/gophermart/internal/app"
+	"github.com/a2sh3r/gophermart/internal/config"
+	"github.com/a2sh3r/gophermart/internal/database"
+	"os"
 	"os/signal"
 	"syscall"
-	"time"
 )
 
 func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+
+	if len(cfg.Command) > 0 {
+		if cfg.Command[0] != "migrate" {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n%s\n", cfg.Command[0], database.MigrateUsage)
+			os.Exit(2)
+		}
+		if err := database.RunMigrateCommand(cfg.DatabaseURI, cfg.Command[1:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if cfg.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	newApp, err := app.NewApp()
+	newApp, err := app.NewApp(cfg)
 	if err != nil {
 		panic(err)
 	}
@@ -22,7 +55,7 @@ func main() {
 	}
 
 	<-ctx.Done()
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ShutdownDrainDelay+cfg.ShutdownTimeout)
 	defer shutdownCancel()
 
 	if err := newApp.Shutdown(shutdownCtx); err != nil {
@@ -5,6 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/metrics"
+	"github.com/a2sh3r/gophermart/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"log"
 	"net/http"
@@ -35,25 +41,43 @@ type Client struct {
 	httpClient *http.Client
 }
 
-func NewClient(baseURL string) *Client {
+const DefaultTimeout = 5 * time.Second
+
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
 	return &Client{
 		baseURL:    baseURL,
-		httpClient: &http.Client{Timeout: 5 * time.Second},
+		httpClient: &http.Client{Timeout: timeout},
 	}
 }
 
-func (c *Client) GetOrderStatus(ctx context.Context, orderNumber string) (*AccrualResponse, int, error) {
+func (c *Client) GetOrderStatus(ctx context.Context, orderNumber string) (_ *AccrualResponse, statusCode int, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "accrual.GetOrderStatus",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("order.number", orderNumber)),
+	)
+	defer func() {
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+		tracing.End(span, err)
+	}()
+
 	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/orders/%s", c.baseURL, orderNumber), nil)
 	if err != nil {
 		return nil, 0, err
 	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
-	logger.Log.Info("fetching accrual", zap.Any("order", orderNumber))
+	logger.FromContext(ctx).Info("fetching accrual", zap.Any("order", orderNumber))
 
+	start := time.Now()
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		metrics.ObserveAccrualRequest(0, time.Since(start))
 		return nil, 0, err
 	}
+	metrics.ObserveAccrualRequest(resp.StatusCode, time.Since(start))
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
 			log.Printf("failed to close res.Body: %v", err)
@@ -75,3 +99,18 @@ func (c *Client) GetOrderStatus(ctx context.Context, orderNumber string) (*Accru
 
 	return &result, resp.StatusCode, nil
 }
+
+// Ping checks that the accrual system answers HTTP requests at all; any
+// response status counts as reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
@@ -8,6 +8,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 func TestClient_GetOrderStatus(t *testing.T) {
@@ -84,7 +87,7 @@ func TestClient_GetOrderStatus(t *testing.T) {
 			}))
 			defer srv.Close()
 
-			client := NewClient(srv.URL)
+			client := NewClient(srv.URL, 0)
 			client.httpClient.Timeout = 2 * time.Second
 
 			resp, status, err := client.GetOrderStatus(context.Background(), "123")
@@ -113,3 +116,38 @@ func TestClient_GetOrderStatus(t *testing.T) {
 func float64Ptr(f float64) *float64 {
 	return &f
 }
+
+func TestClient_GetOrderStatus_PropagatesTraceContext(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	provider := sdktrace.NewTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer func() {
+		_ = provider.Shutdown(context.Background())
+	}()
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "upload")
+	defer span.End()
+
+	_, _, err := NewClient(srv.URL, 0).GetOrderStatus(ctx, "123")
+	assert.NoError(t, err)
+	assert.Contains(t, traceparent, span.SpanContext().TraceID().String())
+}
+
+func TestClient_Ping(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	client := NewClient(srv.URL, 0)
+	assert.NoError(t, client.Ping(context.Background()))
+
+	srv.Close()
+	assert.Error(t, client.Ping(context.Background()))
+}
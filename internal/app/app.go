@@ -7,10 +7,19 @@ import (
 	"fmt"
 	"github.com/a2sh3r/gophermart/internal/accrual"
 	"github.com/a2sh3r/gophermart/internal/database"
+	"github.com/a2sh3r/gophermart/internal/events"
 	"github.com/a2sh3r/gophermart/internal/handlers"
+	"github.com/a2sh3r/gophermart/internal/health"
+	"github.com/a2sh3r/gophermart/internal/metrics"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/notify"
+	"github.com/a2sh3r/gophermart/internal/password"
 	"github.com/a2sh3r/gophermart/internal/repository"
 	"github.com/a2sh3r/gophermart/internal/service"
+	"github.com/a2sh3r/gophermart/internal/tracing"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 	"net/http"
 	"time"
 
@@ -19,21 +28,41 @@ import (
 )
 
 type App struct {
-	server        *http.Server
-	db            *sql.DB
-	orderRepo     repository.OrderRepository
-	balanceRepo   repository.BalanceRepository
-	accrualClient *accrual.Client
+	server          *http.Server
+	db              *sql.DB
+	replica         *sql.DB
+	health          *health.Checker
+	drainDelay      time.Duration
+	shutdownTimeout time.Duration
+	updater         *service.AccrualUpdater
+	orderEvents     service.OrderEventService
+	eventListener   *events.Listener
+	eventsRetention time.Duration
+	webhooks        *service.WebhookDispatcher
+	outboxRelay     *service.OutboxRelay
+	outboxRetention time.Duration
+	shutdownTracing func(context.Context) error
+	cfg             *config.Config
+	limiter         *middleware.UserLimiter
 }
 
-func NewApp() (*App, error) {
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
+func NewApp(cfg *config.Config) (*App, error) {
+	if err := logger.Init(logger.Config{
+		Level:       cfg.LogLevel,
+		Format:      cfg.LogFormat,
+		Sampling:    cfg.LogSampling,
+		OutputPaths: cfg.LogOutput,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
 	}
 
-	if err := logger.Initialize("debug"); err != nil {
-		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		ServiceName: cfg.TracingServiceName,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize tracing: %w", err)
 	}
 
 	db, err := database.InitDB(cfg)
@@ -42,38 +71,143 @@ func NewApp() (*App, error) {
 		return nil, err
 	}
 
-	accrualClient := accrual.NewClient(cfg.AccrualSystemAddress)
+	if err := metrics.RegisterDB(db); err != nil {
+		return nil, fmt.Errorf("failed to register database metrics: %w", err)
+	}
+
+	replica, err := database.InitReplica(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dbRouter := repository.NewDBRouter(db, replica, cfg.DBReplicaReadWindow)
+
+	accrualClient := accrual.NewClient(cfg.AccrualSystemAddress, cfg.AccrualTimeout)
 
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	passwordPolicy := password.NewPolicy(cfg.PasswordMinLength, cfg.PasswordCheckCommon)
+	passwordHasher, err := password.NewHasher(password.HasherConfig{
+		Algorithm: cfg.PasswordHashAlgo,
+		Argon2: password.Argon2Params{
+			Memory:      cfg.Argon2Memory,
+			Iterations:  cfg.Argon2Iterations,
+			Parallelism: cfg.Argon2Parallelism,
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+		BcryptCost: cfg.BcryptCost,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create password hasher: %w", err)
+	}
+	userService := service.NewUserService(userRepo, passwordPolicy, passwordHasher)
 
-	orderRepo := repository.NewOrderRepository(db)
-	balanceRepo := repository.NewBalanceRepository(db)
-	orderService := service.NewOrderService(orderRepo, balanceRepo, accrualClient)
+	orderRepo := repository.NewRoutedOrderRepository(dbRouter)
+	balanceRepo := repository.NewRoutedBalanceRepository(dbRouter)
+	orderService := service.NewOrderService(orderRepo, balanceRepo, accrualClient, cfg.OrderBatchMaxSize)
 
 	balanceService := service.NewBalanceService(balanceRepo)
 
-	handler := handlers.NewHandler(userService, orderService, balanceService, cfg.SecretKey)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo, userRepo, cfg.TOTPIssuer, cfg.WithdrawRequireTOTP)
+
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+
+	adminService := service.NewAdminService(userRepo, orderRepo, balanceRepo)
+
+	accountService := service.NewAccountService(userRepo, orderRepo, balanceRepo, apiKeyRepo, passwordHasher)
+
+	eventBroker := events.NewBroker()
+	orderEventService := service.NewOrderEventService(repository.NewOrderEventRepository(db), eventBroker)
+
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhookService := service.NewWebhookService(webhookRepo, cfg.WebhookAllowPrivate)
+
+	var notificationChannels []notify.Channel
+	if cfg.SMTPAddr != "" {
+		smtpChannel, err := notify.NewSMTPChannel(notify.SMTPConfig{
+			Addr:     cfg.SMTPAddr,
+			From:     cfg.SMTPFrom,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			Timeout:  cfg.SMTPTimeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure SMTP: %w", err)
+		}
+		notificationChannels = append(notificationChannels, smtpChannel)
+	}
+	notificationService := service.NewNotificationService(repository.NewNotificationRepository(db), notificationChannels, cfg.LargeWithdrawal)
 
-	r := handlers.NewRouter(handler, cfg.SecretKey)
+	eventBus := events.NewBus()
+	eventBus.Subscribe("webhooks", webhookService.HandleDomainEvent, models.EventOrderStatusChanged, models.EventWithdrawalMade)
+	eventBus.Subscribe("notifications", notificationService.HandleDomainEvent, models.EventOrderStatusChanged, models.EventWithdrawalMade)
 
+	updater := service.NewAccrualUpdater(orderRepo, balanceRepo, accrualClient, orderEventService, cfg.AccrualPollInterval)
+	updater.SetWorkers(cfg.AccrualWorkers)
+
+	migrationVersion, err := database.LatestMigrationVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine migration version: %w", err)
+	}
+
+	healthChecker := health.NewChecker(cfg.ReadyCheckTimeout)
+	healthChecker.Add("database", db.PingContext)
+	healthChecker.Add("migrations", func(ctx context.Context) error {
+		return database.CheckMigrationVersion(ctx, db, migrationVersion)
+	})
+	healthChecker.Add("accrual", accrualClient.Ping)
+	healthChecker.Add("accrual_updater", func(context.Context) error {
+		if age := time.Since(updater.LastSuccessfulCycle()); age > cfg.ReadyUpdaterMaxAge {
+			return fmt.Errorf("last successful cycle was %s ago", age.Round(time.Second))
+		}
+		return nil
+	})
+
+	handler := handlers.NewHandler(userService, orderService, balanceService, twoFactorService, apiKeyService, adminService, accountService, orderEventService, webhookService, notificationService, healthChecker, cfg.SecretKey)
+
+	limiter := middleware.NewUserRateLimiter(rate.Limit(cfg.RateLimitRPS), cfg.RateLimitBurst)
+	r := handlers.NewRouter(handler, cfg.SecretKey, limiter)
+
+	// No write timeout: order events are streamed over long-lived SSE
+	// responses.
 	server := &http.Server{
-		Addr:    cfg.RunAddress,
-		Handler: r,
+		Addr:              cfg.RunAddress,
+		Handler:           r,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
 	}
+	server.RegisterOnShutdown(handler.CloseStreams)
 
 	return &App{
-		server:        server,
-		db:            db,
-		orderRepo:     orderRepo,
-		balanceRepo:   balanceRepo,
-		accrualClient: accrualClient,
+		server:          server,
+		db:              db,
+		replica:         replica,
+		health:          healthChecker,
+		drainDelay:      cfg.ShutdownDrainDelay,
+		shutdownTimeout: cfg.ShutdownTimeout,
+		updater:         updater,
+		orderEvents:     orderEventService,
+		eventListener:   events.NewListener(db, repository.OrderEventsChannel, eventBroker),
+		eventsRetention: cfg.OrderEventsRetention,
+		webhooks:        service.NewWebhookDispatcher(webhookRepo, cfg.WebhookTimeout, cfg.WebhookMaxAttempts, time.Second*5, cfg.WebhookAllowPrivate),
+		outboxRelay:     service.NewOutboxRelay(repository.NewOutboxRepository(db), eventBus, time.Second),
+		outboxRetention: cfg.OutboxRetention,
+		shutdownTracing: shutdownTracing,
+		cfg:             cfg,
+		limiter:         limiter,
 	}, nil
 }
 
 func (a *App) Run(parentCtx context.Context) error {
-	updater := service.NewAccrualUpdater(a.orderRepo, a.balanceRepo, a.accrualClient, time.Second*5)
-	go updater.Run(parentCtx)
+	go a.updater.Run(parentCtx)
+	go a.watchConfig(parentCtx)
+	go a.eventListener.Run(parentCtx)
+	go a.orderEvents.RunCleanup(parentCtx, time.Hour, a.eventsRetention)
+	go a.webhooks.Run(parentCtx)
+	go a.outboxRelay.Run(parentCtx)
+	go a.outboxRelay.RunCleanup(parentCtx, time.Hour, a.outboxRetention)
 
 	serverErrCh := make(chan error, 1)
 	go func() {
@@ -93,21 +227,44 @@ func (a *App) Run(parentCtx context.Context) error {
 	}
 }
 
+// Shutdown first fails readiness and waits for the drain delay so that load
+// balancers stop sending traffic, then stops the server.
 func (a *App) Shutdown(ctx context.Context) error {
-	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	a.health.SetDraining()
+	if a.drainDelay > 0 {
+		logger.Log.Info("draining before shutdown", zap.Duration("delay", a.drainDelay))
+		select {
+		case <-time.After(a.drainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, a.shutdownTimeout)
 	defer cancel()
 
+	// Resources are released even when the server did not stop in time.
+	var errs []error
 	logger.Log.Info("shutting down server...")
 	if err := a.server.Shutdown(shutdownCtx); err != nil {
 		logger.Log.Error("server shutdown failed", zap.Error(err))
-		return err
+		errs = append(errs, err)
+	}
+
+	if err := a.shutdownTracing(shutdownCtx); err != nil {
+		logger.Log.Error("failed to flush traces", zap.Error(err))
+	}
+
+	if a.replica != nil {
+		if err := a.replica.Close(); err != nil {
+			logger.Log.Error("failed to close replica", zap.Error(err))
+		}
 	}
 
 	logger.Log.Info("closing database connection...")
 	if err := a.db.Close(); err != nil {
 		logger.Log.Error("failed to close database", zap.Error(err))
-		return err
+		errs = append(errs, err)
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
@@ -1,11 +1,17 @@
 package app
 
 import (
+	"github.com/a2sh3r/gophermart/internal/config"
 	"testing"
 )
 
 func TestNewApp(t *testing.T) {
-	_, err := NewApp()
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	_, err = NewApp(cfg)
 	if err != nil {
 		t.Logf("NewApp returned error (this may be expected in test env): %v", err)
 	}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"github.com/a2sh3r/gophermart/internal/config"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// watchConfig reloads the configuration on SIGHUP and, when a config file is
+// used, whenever the file changes.
+func (a *App) watchConfig(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var fileChanged <-chan time.Time
+	var lastMod fileVersion
+	if a.cfg.ConfigFile != "" && a.cfg.ConfigWatchInterval > 0 {
+		ticker := time.NewTicker(a.cfg.ConfigWatchInterval)
+		defer ticker.Stop()
+		fileChanged = ticker.C
+		lastMod = statFile(a.cfg.ConfigFile)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logger.Log.Info("reloading configuration on SIGHUP")
+			a.reloadConfig()
+		case <-fileChanged:
+			if mod := statFile(a.cfg.ConfigFile); mod != lastMod {
+				lastMod = mod
+				logger.Log.Info("reloading configuration after file change", zap.String("file", a.cfg.ConfigFile))
+				a.reloadConfig()
+			}
+		}
+	}
+}
+
+// reloadConfig applies the reloadable settings of a freshly loaded config and
+// reports the changed ones that only take effect after a restart. An invalid
+// config is rejected as a whole.
+func (a *App) reloadConfig() {
+	next, err := a.cfg.Reload()
+	if err != nil {
+		logger.Log.Error("configuration reload rejected", zap.Error(err))
+		return
+	}
+
+	var applied, restart []string
+	for _, key := range a.cfg.Changes(next) {
+		if !config.IsReloadable(key) {
+			restart = append(restart, key)
+			continue
+		}
+
+		switch key {
+		case "rate_limit_rps", "rate_limit_burst":
+			a.cfg.RateLimitRPS = next.RateLimitRPS
+			a.cfg.RateLimitBurst = next.RateLimitBurst
+			a.limiter.SetLimit(rate.Limit(next.RateLimitRPS), next.RateLimitBurst)
+		case "accrual_poll_interval":
+			a.cfg.AccrualPollInterval = next.AccrualPollInterval
+			a.updater.SetPollInterval(next.AccrualPollInterval)
+		case "accrual_workers":
+			a.cfg.AccrualWorkers = next.AccrualWorkers
+			a.updater.SetWorkers(next.AccrualWorkers)
+		case "log_level":
+			a.cfg.LogLevel = next.LogLevel
+			if err := logger.SetLevel(next.LogLevel); err != nil {
+				logger.Log.Error("failed to change log level", zap.Error(err))
+				continue
+			}
+		}
+		applied = append(applied, key)
+	}
+
+	if len(applied) > 0 {
+		logger.Log.Info("configuration reloaded", zap.Strings("applied", applied))
+	}
+	if len(restart) > 0 {
+		logger.Log.Warn("changed settings require a restart", zap.Strings("settings", restart))
+	}
+}
+
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) fileVersion {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileVersion{}
+	}
+	return fileVersion{modTime: info.ModTime(), size: info.Size()}
+}
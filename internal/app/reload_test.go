@@ -0,0 +1,47 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a2sh3r/gophermart/internal/config"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func TestApp_reloadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gophermart.yaml")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	write("rate_limit_rps: 10\naccrual_poll_interval: 5s\nlog_level: info\nrun_address: localhost:9000\n")
+	cfg, err := config.Load([]string{"-config", path})
+	require.NoError(t, err)
+	require.NoError(t, logger.Init(logger.Config{Level: cfg.LogLevel, Format: logger.FormatJSON}))
+
+	a := &App{
+		cfg:     cfg,
+		limiter: middleware.NewUserRateLimiter(rate.Limit(cfg.RateLimitRPS), cfg.RateLimitBurst),
+		updater: service.NewAccrualUpdater(nil, nil, nil, nil, cfg.AccrualPollInterval),
+	}
+
+	write("rate_limit_rps: 20\naccrual_poll_interval: 1s\naccrual_workers: 4\nlog_level: debug\nrun_address: localhost:9001\n")
+	a.reloadConfig()
+
+	assert.Equal(t, 20.0, a.cfg.RateLimitRPS)
+	assert.Equal(t, time.Second, a.cfg.AccrualPollInterval)
+	assert.Equal(t, 4, a.cfg.AccrualWorkers)
+	assert.Equal(t, "debug", logger.Level())
+	assert.Equal(t, "localhost:9000", a.cfg.RunAddress, "restart-only settings keep their running value")
+
+	write("rate_limit_rps: -1\n")
+	a.reloadConfig()
+	assert.Equal(t, 20.0, a.cfg.RateLimitRPS, "invalid config is rejected")
+}
@@ -15,4 +15,29 @@ var (
 	ErrInvalidOrderNumber   = errors.New("invalid order number")
 	ErrInsufficientFunds    = errors.New("insufficient funds")
 	ErrInvalidWithdrawalSum = errors.New("invalid withdrawal sum")
+	ErrWeakPassword         = errors.New("password does not satisfy policy")
+	ErrSamePassword         = errors.New("new password must differ from current password")
+	ErrTOTPNotConfigured    = errors.New("two-factor authentication is not configured")
+	ErrTOTPAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrTOTPRequired         = errors.New("two-factor code required")
+	ErrInvalidTOTPCode      = errors.New("invalid two-factor code")
+	ErrTOTPLocked           = errors.New("too many invalid two-factor codes")
+	ErrInvalidChallenge     = errors.New("invalid or expired two-factor challenge")
+	ErrInvalidAPIKey        = errors.New("invalid or revoked API key")
+	ErrAPIKeyNotFound       = errors.New("API key not found")
+	ErrInvalidScope         = errors.New("invalid API key scope")
+	ErrInvalidRole          = errors.New("invalid role")
+	ErrCannotChangeOwnRole  = errors.New("cannot change own role")
+	ErrInvalidAdjustment    = errors.New("invalid balance adjustment")
+	ErrInvalidCursor        = errors.New("invalid pagination cursor")
+	ErrInvalidPageLimit     = errors.New("invalid page limit")
+	ErrInvalidFilter        = errors.New("invalid filter")
+	ErrOrderNotFound        = errors.New("order not found")
+	ErrEmptyBatch           = errors.New("order batch is empty")
+	ErrBatchTooLarge        = errors.New("order batch is too large")
+	ErrInvalidWebhookURL    = errors.New("invalid webhook URL")
+	ErrInvalidWebhookEvent  = errors.New("invalid webhook event type")
+	ErrWebhookNotFound      = errors.New("webhook not found")
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrInvalidEmail         = errors.New("invalid email address")
 )
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "localhost:8084", cfg.RunAddress)
+	assert.Equal(t, 5*time.Second, cfg.AccrualPollInterval)
+	assert.Equal(t, []string{"stderr"}, cfg.LogOutput)
+	assert.False(t, cfg.PrintConfig)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeConfigFile(t, "gophermart.yaml", `
+run_address: file:1
+database_uri: postgres://file/db
+accrual_poll_interval: 30s
+rate_limit_rps: 5
+log_output: [stdout]
+`)
+
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("DATABASE_URI", "postgres://env/db")
+	t.Setenv("RUN_ADDRESS", "env:1")
+
+	cfg, err := Load([]string{"-a", "flag:1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "flag:1", cfg.RunAddress, "flag beats env and file")
+	assert.Equal(t, "postgres://env/db", cfg.DatabaseURI, "env beats file")
+	assert.Equal(t, 30*time.Second, cfg.AccrualPollInterval, "file beats default")
+	assert.Equal(t, 5.0, cfg.RateLimitRPS)
+	assert.Equal(t, []string{"stdout"}, cfg.LogOutput)
+	assert.Equal(t, 1000, cfg.RateLimitBurst, "default kept when nothing overrides it")
+	assert.Equal(t, path, cfg.ConfigFile)
+}
+
+func TestLoad_JSONFile(t *testing.T) {
+	path := writeConfigFile(t, "gophermart.json", `{"accrual_timeout": "2s", "order_batch_max_size": 10}`)
+
+	cfg, err := Load([]string{"-config", path})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2*time.Second, cfg.AccrualTimeout)
+	assert.Equal(t, 10, cfg.OrderBatchMaxSize)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		wantErr []string
+	}{
+		{
+			name:    "missing config file",
+			args:    []string{"-config", "/nonexistent/gophermart.yaml"},
+			wantErr: []string{"failed to read config file"},
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"-x"},
+			wantErr: []string{"flag provided but not defined"},
+		},
+		{
+			name: "all validation errors are reported",
+			env: map[string]string{
+				"APP_ENV":               "production",
+				"KEY":                   "",
+				"ACCRUAL_POLL_INTERVAL": "0s",
+				"RATE_LIMIT_BURST":      "0",
+				"DB_MAX_IDLE_CONNS":     "50",
+			},
+			wantErr: []string{
+				"KEY must be set in production",
+				"ACCRUAL_POLL_INTERVAL must be positive",
+				"RATE_LIMIT_BURST must be positive",
+				"DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS",
+			},
+		},
+		{
+			name: "password hash parameters",
+			env: map[string]string{
+				"ARGON2_ITERATIONS":  "0",
+				"ARGON2_PARALLELISM": "0",
+				"BCRYPT_COST":        "40",
+			},
+			wantErr: []string{
+				"ARGON2_ITERATIONS must be at least 1",
+				"ARGON2_PARALLELISM must be at least 1",
+				"BCRYPT_COST must be between 4 and 31",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := Load(tt.args)
+			require.Error(t, err)
+			for _, want := range tt.wantErr {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestConfig_Print(t *testing.T) {
+	t.Setenv("KEY", "super-secret")
+	t.Setenv("SMTP_PASSWORD", "mail-secret")
+	t.Setenv("DATABASE_URI", "postgres://app:db-secret@db:5432/gophermart")
+
+	cfg, err := Load([]string{"--print-config"})
+	require.NoError(t, err)
+	assert.True(t, cfg.PrintConfig)
+
+	var buf bytes.Buffer
+	require.NoError(t, cfg.Print(&buf))
+	out := buf.String()
+
+	for _, secret := range []string{"super-secret", "mail-secret", "db-secret"} {
+		assert.NotContains(t, out, secret)
+	}
+	assert.Contains(t, out, "database_uri: postgres://app:xxxxx@db:5432/gophermart")
+	assert.True(t, strings.Contains(out, "key: '****'") || strings.Contains(out, `key: "****"`), out)
+	assert.Equal(t, "super-secret", cfg.SecretKey, "masking must not modify the config")
+}
+
+func TestConfig_MaskedKeywordDSN(t *testing.T) {
+	cfg, err := Load(nil)
+	require.NoError(t, err)
+	cfg.DatabaseURI = "host=db user=app password=db-secret dbname=gophermart"
+	cfg.DatabaseReplicaURI = "postgres://replica/gophermart?user=app&password=replica-secret"
+
+	var buf bytes.Buffer
+	require.NoError(t, cfg.Print(&buf))
+	out := buf.String()
+
+	for _, secret := range []string{"db-secret", "replica-secret"} {
+		assert.NotContains(t, out, secret)
+	}
+	assert.Contains(t, out, "host=db user=app password=[REDACTED] dbname=gophermart")
+}
+
+func TestConfig_Changes(t *testing.T) {
+	cfg, err := Load(nil)
+	require.NoError(t, err)
+
+	next := *cfg
+	next.RateLimitRPS = 1
+	next.LogOutput = []string{"stdout"}
+	next.ConfigFile = "other.yaml"
+
+	assert.Equal(t, []string{"rate_limit_rps", "log_output"}, cfg.Changes(&next))
+	assert.True(t, IsReloadable("rate_limit_rps"))
+	assert.False(t, IsReloadable("log_output"))
+}
@@ -1,58 +1,194 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"github.com/a2sh3r/gophermart/internal/config"
 	"github.com/a2sh3r/gophermart/internal/logger"
 	"go.uber.org/zap"
+	"time"
 
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+const (
+	PoolSQL     = "sql"
+	PoolPgxpool = "pgxpool"
+
+	connectBaseBackoff = 500 * time.Millisecond
+	connectMaxBackoff  = 5 * time.Second
 )
 
 func InitDB(cfg *config.Config) (*sql.DB, error) {
-	db, err := sql.Open("pgx", cfg.DatabaseURI)
+	db, err := openDB(cfg, cfg.DatabaseURI)
 	if err != nil {
 		return nil, fmt.Errorf("unable to open database: %v", err)
 	}
 
-	err = db.Ping()
-	if err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DBConnectTimeout)
+	defer cancel()
+	if err := pingWithRetry(ctx, db); err != nil {
+		_ = db.Close()
 		return nil, fmt.Errorf("unable to ping database: %v", err)
 	}
 
-	if err := runMigrations(cfg.DatabaseURI); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %v", err)
+	if cfg.DBAutoMigrate {
+		if err := runMigrations(cfg.DatabaseURI); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to run migrations: %v", err)
+		}
 	}
 
-	logger.Log.Info("Successfully connected to the database", zap.Any("database dsn", cfg.DatabaseURI))
+	logger.Log.Info("Successfully connected to the database", zap.String("database", logger.Redact(cfg.DatabaseURI)), zap.String("pool", cfg.DBPool))
 	return db, nil
 }
 
-func runMigrations(dsn string) error {
-	m, err := migrate.New(
-		"file://internal/migrations",
-		dsn,
-	)
+// InitReplica opens the read replica, or returns nil when none is configured.
+// An unreachable replica is not fatal: reads fall back to the primary until
+// it comes up.
+func InitReplica(cfg *config.Config) (*sql.DB, error) {
+	if cfg.DatabaseReplicaURI == "" {
+		return nil, nil
+	}
+
+	db, err := openDB(cfg, cfg.DatabaseReplicaURI)
 	if err != nil {
-		logger.Log.Error("failed to create migrate instance: %v", zap.Error(err))
-		return fmt.Errorf("failed to create migrate instance: %v", err)
+		return nil, fmt.Errorf("unable to open replica: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DBConnectTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		logger.Log.Warn("replica is not reachable, reads will use the primary", zap.Error(err))
+		return db, nil
 	}
-	defer func(m *migrate.Migrate) {
-		err, _ := m.Close()
+
+	logger.Log.Info("Successfully connected to the replica", zap.String("database", logger.Redact(cfg.DatabaseReplicaURI)))
+	return db, nil
+}
+
+// openDB builds the connection pool for dsn. With PoolPgxpool connections are managed
+// by a native pgxpool and database/sql only borrows them, so repositories keep
+// working with *sql.DB either way. Both use pgx's prepared statement cache
+// unless its size is 0.
+func openDB(cfg *config.Config, dsn string) (*sql.DB, error) {
+	switch cfg.DBPool {
+	case PoolSQL:
+		connConfig, err := pgx.ParseConfig(dsn)
+		if err != nil {
+			return nil, err
+		}
+		setStatementCache(connConfig, cfg.DBStatementCacheSize)
+
+		db := stdlib.OpenDB(*connConfig)
+		db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+		db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+		db.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+		db.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+		return db, nil
+	case PoolPgxpool:
+		poolConfig, err := pgxpool.ParseConfig(dsn)
+		if err != nil {
+			return nil, err
+		}
+		setStatementCache(poolConfig.ConnConfig, cfg.DBStatementCacheSize)
+		if cfg.DBMaxOpenConns > 0 {
+			poolConfig.MaxConns = int32(cfg.DBMaxOpenConns)
+		}
+		poolConfig.MinConns = int32(min(cfg.DBMaxIdleConns, int(poolConfig.MaxConns)))
+		if cfg.DBConnMaxLifetime > 0 {
+			poolConfig.MaxConnLifetime = cfg.DBConnMaxLifetime
+		}
+		if cfg.DBConnMaxIdleTime > 0 {
+			poolConfig.MaxConnIdleTime = cfg.DBConnMaxIdleTime
+		}
+
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 		if err != nil {
-			logger.Log.Error("failed to close during migration: %v", zap.Error(err))
+			return nil, err
 		}
-	}(m)
 
-	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return fmt.Errorf("failed to run migrations: %v", err)
+		db := sql.OpenDB(poolConnector{Connector: stdlib.GetPoolConnector(pool), pool: pool})
+		db.SetMaxIdleConns(0)
+		return db, nil
+	default:
+		return nil, fmt.Errorf("unknown pool %q", cfg.DBPool)
 	}
+}
 
-	logger.Log.Info("Migrations completed successfully")
+// setStatementCache sizes the prepared statement cache. pgx refuses to run
+// queries in the default cache-statement mode once the cache is disabled, so
+// a size of 0 switches to describing each statement instead.
+func setStatementCache(connConfig *pgx.ConnConfig, size int) {
+	connConfig.StatementCacheCapacity = size
+	if size == 0 && connConfig.DefaultQueryExecMode == pgx.QueryExecModeCacheStatement {
+		connConfig.DefaultQueryExecMode = pgx.QueryExecModeDescribeExec
+	}
+}
+
+// poolConnector closes the pgxpool together with the *sql.DB built on it.
+type poolConnector struct {
+	driver.Connector
+	pool *pgxpool.Pool
+}
+
+func (c poolConnector) Close() error {
+	c.pool.Close()
+	return nil
+}
+
+// pingWithRetry waits for the database to accept connections, backing off
+// between attempts until ctx expires. It smooths over Postgres starting up
+// alongside the service.
+func pingWithRetry(ctx context.Context, db *sql.DB) error {
+	backoff := connectBaseBackoff
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+
+		logger.Log.Warn("database is not reachable yet", zap.Int("attempt", attempt), zap.Duration("retryIn", backoff), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, connectMaxBackoff)
+	}
+}
+
+// CheckMigrationVersion reports an error unless the schema is clean and at
+// least at the expected version.
+func CheckMigrationVersion(ctx context.Context, db *sql.DB, expected uint) error {
+	var (
+		version uint
+		dirty   bool
+	)
+	err := db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("no migrations applied")
+	}
+	if err != nil {
+		return err
+	}
+
+	return checkSchemaVersion(version, dirty, expected)
+}
+
+// checkSchemaVersion accepts a schema newer than expected: during a rolling
+// deploy the new release migrates forward while old instances keep serving.
+func checkSchemaVersion(version uint, dirty bool, expected uint) error {
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < expected {
+		return fmt.Errorf("schema is at version %d, expected at least %d", version, expected)
+	}
 	return nil
 }
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/migrations"
+	"go.uber.org/zap"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+func newMigrate(dsn string) (*migrate.Migrate, error) {
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedded migrations: %v", err)
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrate instance: %v", err)
+	}
+	return m, nil
+}
+
+func closeMigrate(m *migrate.Migrate) {
+	srcErr, dbErr := m.Close()
+	if err := errors.Join(srcErr, dbErr); err != nil {
+		logger.Log.Error("failed to close migrate instance", zap.Error(err))
+	}
+}
+
+func runMigrations(dsn string) error {
+	m, err := newMigrate(dsn)
+	if err != nil {
+		logger.Log.Error("failed to create migrate instance", zap.Error(err))
+		return err
+	}
+	defer closeMigrate(m)
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to run migrations: %v", err)
+	}
+
+	logger.Log.Info("Migrations completed successfully")
+	return nil
+}
+
+// LatestMigrationVersion returns the highest version among the embedded
+// migrations, i.e. the version a fully migrated database should be at.
+func LatestMigrationVersion() (uint, error) {
+	files, err := fs.Glob(migrations.FS, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, file := range files {
+		prefix, _, ok := strings.Cut(file, "_")
+		if !ok {
+			continue
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		latest = max(latest, uint(version))
+	}
+	if latest == 0 {
+		return 0, errors.New("no embedded migrations found")
+	}
+	return latest, nil
+}
+
+type migrateCommand struct {
+	name string
+	arg  int
+}
+
+const MigrateUsage = "usage: gophermart [flags] migrate up|down [N]|goto VERSION|version|force VERSION"
+
+func parseMigrateCommand(args []string) (migrateCommand, error) {
+	if len(args) == 0 {
+		return migrateCommand{}, errors.New(MigrateUsage)
+	}
+
+	cmd := migrateCommand{name: args[0]}
+	rest := args[1:]
+
+	switch cmd.name {
+	case "up", "version":
+		if len(rest) != 0 {
+			return cmd, errors.New(MigrateUsage)
+		}
+	case "down":
+		cmd.arg = 1
+		if len(rest) > 1 {
+			return cmd, errors.New(MigrateUsage)
+		}
+		if len(rest) == 1 {
+			n, err := strconv.Atoi(rest[0])
+			if err != nil || n <= 0 {
+				return cmd, fmt.Errorf("down expects a positive number of steps, got %q", rest[0])
+			}
+			cmd.arg = n
+		}
+	case "goto", "force":
+		if len(rest) != 1 {
+			return cmd, errors.New(MigrateUsage)
+		}
+		v, err := strconv.Atoi(rest[0])
+		if err != nil || v < 0 || (cmd.name == "goto" && v == 0) {
+			return cmd, fmt.Errorf("%s expects a migration version, got %q", cmd.name, rest[0])
+		}
+		cmd.arg = v
+	default:
+		return cmd, fmt.Errorf("unknown migrate command %q\n%s", cmd.name, MigrateUsage)
+	}
+	return cmd, nil
+}
+
+// RunMigrateCommand executes a `gophermart migrate` subcommand against dsn
+// and prints the resulting schema version to out.
+func RunMigrateCommand(dsn string, args []string, out io.Writer) error {
+	cmd, err := parseMigrateCommand(args)
+	if err != nil {
+		return err
+	}
+
+	m, err := newMigrate(dsn)
+	if err != nil {
+		return err
+	}
+	defer closeMigrate(m)
+
+	switch cmd.name {
+	case "up":
+		err = m.Up()
+	case "down":
+		err = m.Steps(-cmd.arg)
+	case "goto":
+		err = m.Migrate(uint(cmd.arg))
+	case "force":
+		err = m.Force(cmd.arg)
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		_, err = fmt.Fprintln(out, "no migrations applied")
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "version %d (dirty: %t)\n", version, dirty)
+	return err
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/a2sh3r/gophermart/internal/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	ups, err := fs.Glob(migrations.FS, "*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, ups)
+
+	for _, up := range ups {
+		down := strings.TrimSuffix(up, ".up.sql") + ".down.sql"
+		_, err := fs.Stat(migrations.FS, down)
+		assert.NoError(t, err, "missing down migration for %s", up)
+	}
+
+	latest, err := LatestMigrationVersion()
+	require.NoError(t, err)
+	assert.EqualValues(t, len(ups), latest)
+}
+
+func TestParseMigrateCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    migrateCommand
+		wantErr bool
+	}{
+		{name: "up", args: []string{"up"}, want: migrateCommand{name: "up"}},
+		{name: "version", args: []string{"version"}, want: migrateCommand{name: "version"}},
+		{name: "down defaults to one step", args: []string{"down"}, want: migrateCommand{name: "down", arg: 1}},
+		{name: "down N", args: []string{"down", "3"}, want: migrateCommand{name: "down", arg: 3}},
+		{name: "goto", args: []string{"goto", "12"}, want: migrateCommand{name: "goto", arg: 12}},
+		{name: "force", args: []string{"force", "0"}, want: migrateCommand{name: "force", arg: 0}},
+		{name: "no command", wantErr: true},
+		{name: "unknown command", args: []string{"redo"}, wantErr: true},
+		{name: "up with argument", args: []string{"up", "2"}, wantErr: true},
+		{name: "down with zero steps", args: []string{"down", "0"}, wantErr: true},
+		{name: "goto without version", args: []string{"goto"}, wantErr: true},
+		{name: "goto zero", args: []string{"goto", "0"}, wantErr: true},
+		{name: "force not a number", args: []string{"force", "latest"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMigrateCommand(tt.args)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
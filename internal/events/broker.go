@@ -0,0 +1,71 @@
+package events
+
+import "sync"
+
+// Broker fans out per-user wake-up signals to in-process subscribers.
+// Signals carry no payload: a subscriber that wakes up reads whatever it has
+// not seen yet from storage, so coalesced or missed signals lose nothing.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[int64]map[*Subscription]struct{}
+}
+
+type Subscription struct {
+	C <-chan struct{}
+
+	c      chan struct{}
+	userID int64
+	broker *Broker
+	once   sync.Once
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[int64]map[*Subscription]struct{})}
+}
+
+func (b *Broker) Subscribe(userID int64) *Subscription {
+	c := make(chan struct{}, 1)
+	sub := &Subscription{C: c, c: c, userID: userID, broker: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[*Subscription]struct{})
+	}
+	b.subscribers[userID][sub] = struct{}{}
+
+	return sub
+}
+
+// Notify wakes every subscriber of the user without blocking. A subscriber
+// that has not consumed the previous signal yet keeps a single pending one.
+func (b *Broker) Notify(userID int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers[userID] {
+		select {
+		case sub.c <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (b *Broker) SubscriberCount(userID int64) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers[userID])
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.broker.mu.Lock()
+		defer s.broker.mu.Unlock()
+
+		delete(s.broker.subscribers[s.userID], s)
+		if len(s.broker.subscribers[s.userID]) == 0 {
+			delete(s.broker.subscribers, s.userID)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBroker_Notify(t *testing.T) {
+	b := NewBroker()
+
+	sub := b.Subscribe(1)
+	defer sub.Close()
+	other := b.Subscribe(2)
+	defer other.Close()
+
+	b.Notify(1)
+	b.Notify(1)
+
+	select {
+	case <-sub.C:
+	case <-time.After(time.Second):
+		t.Fatal("subscriber was not notified")
+	}
+
+	select {
+	case <-sub.C:
+		t.Fatal("repeated notifications must be coalesced")
+	default:
+	}
+
+	select {
+	case <-other.C:
+		t.Fatal("subscriber of another user must not be notified")
+	default:
+	}
+}
+
+func TestBroker_Close(t *testing.T) {
+	b := NewBroker()
+
+	first := b.Subscribe(1)
+	second := b.Subscribe(1)
+	if got := b.SubscriberCount(1); got != 2 {
+		t.Fatalf("got %d subscribers, want 2", got)
+	}
+
+	first.Close()
+	first.Close()
+	if got := b.SubscriberCount(1); got != 1 {
+		t.Fatalf("got %d subscribers, want 1", got)
+	}
+
+	b.Notify(1)
+	select {
+	case <-second.C:
+	default:
+		t.Fatal("remaining subscriber was not notified")
+	}
+
+	second.Close()
+	if got := b.SubscriberCount(1); got != 0 {
+		t.Fatalf("got %d subscribers, want 0", got)
+	}
+}
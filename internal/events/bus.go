@@ -0,0 +1,63 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"sync"
+)
+
+// Handler reacts to a domain event. The outbox relay delivers events at least
+// once, so handlers must tolerate seeing the same event ID again.
+type Handler func(ctx context.Context, event models.DomainEvent) error
+
+type subscriber struct {
+	name   string
+	handle Handler
+}
+
+// Bus routes domain events relayed from the outbox to in-process subscribers.
+type Bus struct {
+	mu       sync.RWMutex
+	byType   map[string][]subscriber
+	wildcard []subscriber
+}
+
+func NewBus() *Bus {
+	return &Bus{byType: make(map[string][]subscriber)}
+}
+
+// Subscribe registers handler under name for the given event types, or for
+// every event type when none are given. The name only appears in errors.
+func (b *Bus) Subscribe(name string, handler Handler, eventTypes ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := subscriber{name: name, handle: handler}
+	if len(eventTypes) == 0 {
+		b.wildcard = append(b.wildcard, sub)
+		return
+	}
+	for _, eventType := range eventTypes {
+		b.byType[eventType] = append(b.byType[eventType], sub)
+	}
+}
+
+// Dispatch calls every subscriber of the event type in registration order and
+// returns their joined errors. A failing subscriber does not stop the rest.
+func (b *Bus) Dispatch(ctx context.Context, event models.DomainEvent) error {
+	b.mu.RLock()
+	subs := make([]subscriber, 0, len(b.byType[event.Type])+len(b.wildcard))
+	subs = append(subs, b.byType[event.Type]...)
+	subs = append(subs, b.wildcard...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, sub := range subs {
+		if err := sub.handle(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sub.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
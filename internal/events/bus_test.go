@@ -0,0 +1,49 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/a2sh3r/gophermart/internal/models"
+)
+
+func TestBus_Dispatch(t *testing.T) {
+	b := NewBus()
+
+	var calls []string
+	record := func(name string, err error) Handler {
+		return func(_ context.Context, event models.DomainEvent) error {
+			calls = append(calls, name+":"+event.Type)
+			return err
+		}
+	}
+
+	b.Subscribe("orders", record("orders", nil), models.EventOrderUploaded, models.EventOrderStatusChanged)
+	b.Subscribe("failing", record("failing", errors.New("boom")), models.EventOrderUploaded)
+	b.Subscribe("audit", record("audit", nil))
+
+	err := b.Dispatch(context.Background(), models.DomainEvent{Type: models.EventOrderUploaded})
+	if err == nil || err.Error() != "failing: boom" {
+		t.Fatalf("expected joined subscriber error, got %v", err)
+	}
+
+	if err := b.Dispatch(context.Background(), models.DomainEvent{Type: models.EventWithdrawalMade}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"orders:" + models.EventOrderUploaded,
+		"failing:" + models.EventOrderUploaded,
+		"audit:" + models.EventOrderUploaded,
+		"audit:" + models.EventWithdrawalMade,
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("got calls %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("call %d: got %s, want %s", i, calls[i], want[i])
+		}
+	}
+}
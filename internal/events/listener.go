@@ -0,0 +1,99 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Listener relays Postgres NOTIFY messages to the broker so that events
+// written by any instance reach subscribers connected to this one.
+type Listener struct {
+	db      *sql.DB
+	channel string
+	broker  *Broker
+}
+
+func NewListener(db *sql.DB, channel string, broker *Broker) *Listener {
+	return &Listener{db: db, channel: channel, broker: broker}
+}
+
+// Run keeps a dedicated connection listening on the channel until ctx is
+// cancelled, reconnecting with exponential backoff when it is lost.
+func (l *Listener) Run(ctx context.Context) {
+	delay := minReconnectDelay
+	for {
+		started := time.Now()
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if time.Since(started) > maxReconnectDelay {
+			delay = minReconnectDelay
+		}
+		logger.Log.Warn("event listener disconnected", zap.String("channel", l.channel), zap.Error(err), zap.Duration("retryIn", delay))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil && !errors.Is(err, sql.ErrConnDone) {
+			logger.Log.Error("failed to close listener connection", zap.Error(err))
+		}
+	}()
+
+	return conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unsupported driver connection %T", driverConn)
+		}
+		pgConn := stdConn.Conn()
+
+		if _, err := pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+			return err
+		}
+		logger.Log.Info("listening for events", zap.String("channel", l.channel))
+
+		for {
+			notification, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+
+			userID, err := strconv.ParseInt(notification.Payload, 10, 64)
+			if err != nil {
+				logger.Log.Warn("invalid event notification payload", zap.String("payload", notification.Payload))
+				continue
+			}
+			l.broker.Notify(userID)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+func (h *Handler) ExportUserData(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	export, err := h.accountService.Export(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to export user data", zap.Int64("userID", userID), zap.Error(err))
+		return
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="gophermart-export.json"`)
+	writeJSON(w, http.StatusOK, export)
+}
+
+func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Password == "" {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	err := h.accountService.Delete(r.Context(), userID, req.Password)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, apperrors.ErrInvalidCredentials):
+		http.Error(w, "invalid password", http.StatusUnauthorized)
+	case errors.Is(err, apperrors.ErrUserNotFound):
+		http.Error(w, "user not found", http.StatusNotFound)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to delete account", zap.Int64("userID", userID), zap.Error(err))
+	}
+}
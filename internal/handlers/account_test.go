@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/mocks/service_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_ExportUserData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockAccountService := service_mocks.NewMockAccountService(ctrl)
+	h := &Handler{accountService: mockAccountService}
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				mockAccountService.EXPECT().Export(gomock.Any(), int64(1)).Return(&models.UserExport{Profile: models.UserInfo{ID: 1, Login: "user1"}}, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "service error",
+			mockSetup: func() {
+				mockAccountService.EXPECT().Export(gomock.Any(), int64(1)).Return(nil, errors.New("fail"))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodGet, "/api/user/export", nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+			w := httptest.NewRecorder()
+			h.ExportUserData(w, req)
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantStatusCode)
+			}
+			if tt.wantStatusCode == http.StatusOK && w.Header().Get("Content-Disposition") == "" {
+				t.Error("export must be served as an attachment")
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockAccountService := service_mocks.NewMockAccountService(ctrl)
+	h := &Handler{accountService: mockAccountService}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success",
+			body: `{"password":"password123"}`,
+			mockSetup: func() {
+				mockAccountService.EXPECT().Delete(gomock.Any(), int64(1), "password123").Return(nil)
+			},
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name: "wrong password",
+			body: `{"password":"wrong"}`,
+			mockSetup: func() {
+				mockAccountService.EXPECT().Delete(gomock.Any(), int64(1), "wrong").Return(apperrors.ErrInvalidCredentials)
+			},
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name: "already deleted",
+			body: `{"password":"password123"}`,
+			mockSetup: func() {
+				mockAccountService.EXPECT().Delete(gomock.Any(), int64(1), "password123").Return(apperrors.ErrUserNotFound)
+			},
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:           "missing password",
+			body:           `{}`,
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodDelete, "/api/user", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+			w := httptest.NewRecorder()
+			h.DeleteAccount(w, req)
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantStatusCode)
+			}
+		})
+	}
+}
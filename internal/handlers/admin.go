@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) AdminFindUser(w http.ResponseWriter, r *http.Request) {
+	login := r.URL.Query().Get("login")
+	if login == "" {
+		http.Error(w, "login query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.adminService.FindUserByLogin(r.Context(), login)
+	if err != nil {
+		writeAdminError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (h *Handler) AdminGetUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.GetUser(r.Context(), userID)
+	if err != nil {
+		writeAdminError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+func (h *Handler) AdminGetUserOrders(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	orders, err := h.adminService.GetUserOrders(r.Context(), userID)
+	if err != nil {
+		writeAdminError(w, r, err)
+		return
+	}
+	if len(orders) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, orders)
+}
+
+func (h *Handler) AdminGetUserBalance(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	balance, err := h.adminService.GetUserBalance(r.Context(), userID)
+	if err != nil {
+		writeAdminError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, balance)
+}
+
+func (h *Handler) AdminGetUserWithdrawals(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	withdrawals, err := h.adminService.GetUserWithdrawals(r.Context(), userID)
+	if err != nil {
+		writeAdminError(w, r, err)
+		return
+	}
+	if len(withdrawals) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, withdrawals)
+}
+
+func (h *Handler) AdminSetUserRole(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.adminService.SetUserRole(r.Context(), actorID, userID, req.Role); err != nil {
+		writeAdminError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) AdminAdjustBalance(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.BalanceAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	adjustment, err := h.adminService.AdjustBalance(r.Context(), actorID, userID, req)
+	if err != nil {
+		writeAdminError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, adjustment)
+}
+
+func (h *Handler) AdminGetAdjustments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	adjustments, err := h.adminService.GetAdjustments(r.Context(), userID)
+	if err != nil {
+		writeAdminError(w, r, err)
+		return
+	}
+	if len(adjustments) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, adjustments)
+}
+
+func userIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || userID <= 0 {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return 0, false
+	}
+	return userID, true
+}
+
+func (h *Handler) AdminGetLogLevel(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, models.LogLevel{Level: logger.Level()})
+}
+
+func (h *Handler) AdminSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req models.LogLevel
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	previous := logger.Level()
+	if err := logger.SetLevel(req.Level); err != nil {
+		http.Error(w, "invalid log level", http.StatusBadRequest)
+		return
+	}
+	logger.FromContext(r.Context()).Warn("log level changed", zap.String("from", previous), zap.String("to", logger.Level()))
+
+	writeJSON(w, http.StatusOK, models.LogLevel{Level: logger.Level()})
+}
+
+func writeAdminError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrUserNotFound):
+		http.Error(w, "user not found", http.StatusNotFound)
+	case errors.Is(err, apperrors.ErrInvalidRole):
+		http.Error(w, "invalid role", http.StatusBadRequest)
+	case errors.Is(err, apperrors.ErrCannotChangeOwnRole):
+		http.Error(w, "cannot change own role", http.StatusConflict)
+	case errors.Is(err, apperrors.ErrInvalidAdjustment):
+		http.Error(w, "amount must be non-zero and reason and ticket_ref are required", http.StatusBadRequest)
+	case errors.Is(err, apperrors.ErrInsufficientFunds):
+		http.Error(w, "insufficient funds", http.StatusPaymentRequired)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("admin request failed", zap.Error(err))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Log.Error("failed to encode json response", zap.Error(err))
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/mocks/service_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testAccessToken(t *testing.T, secret string, userID int64) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":       userID,
+		"token_version": 0,
+		"exp":           time.Now().Add(time.Hour).Unix(),
+	})
+	tokenString, err := token.SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return tokenString
+}
+
+func TestRouter_AdminRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockUserService := service_mocks.NewMockUserService(ctrl)
+	mockAdminService := service_mocks.NewMockAdminService(ctrl)
+	h := &Handler{userService: mockUserService, adminService: mockAdminService}
+	router := NewRouter(h, "testsecret", middleware.NewUserRateLimiter(1000, 1000))
+
+	roles := map[int64]string{1: models.RoleUser, 2: models.RoleSupport, 3: models.RoleAdmin}
+	mockUserService.EXPECT().GetTokenVersion(gomock.Any(), gomock.Any()).Return(int64(0), nil).AnyTimes()
+	mockUserService.EXPECT().GetUserRole(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, userID int64) (string, error) {
+		return roles[userID], nil
+	}).AnyTimes()
+
+	mockAdminService.EXPECT().GetUser(gomock.Any(), int64(10)).Return(&models.UserInfo{ID: 10, Login: "customer"}, nil).Times(2)
+	mockAdminService.EXPECT().GetUser(gomock.Any(), int64(11)).Return(nil, apperrors.ErrUserNotFound)
+	mockAdminService.EXPECT().SetUserRole(gomock.Any(), int64(3), int64(10), models.RoleSupport).Return(nil)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		userID int64
+		status int
+	}{
+		{"user cannot read", http.MethodGet, "/api/admin/users/10", "", 1, http.StatusForbidden},
+		{"support can read", http.MethodGet, "/api/admin/users/10", "", 2, http.StatusOK},
+		{"admin can read", http.MethodGet, "/api/admin/users/10", "", 3, http.StatusOK},
+		{"missing user", http.MethodGet, "/api/admin/users/11", "", 2, http.StatusNotFound},
+		{"invalid user id", http.MethodGet, "/api/admin/users/abc", "", 2, http.StatusBadRequest},
+		{"support cannot mutate", http.MethodPut, "/api/admin/users/10/role", `{"role":"support"}`, 2, http.StatusForbidden},
+		{"admin can mutate", http.MethodPut, "/api/admin/users/10/role", `{"role":"support"}`, 3, http.StatusOK},
+		{"support can read log level", http.MethodGet, "/api/admin/log-level", "", 2, http.StatusOK},
+		{"support cannot change log level", http.MethodPut, "/api/admin/log-level", `{"level":"debug"}`, 2, http.StatusForbidden},
+		{"admin can change log level", http.MethodPut, "/api/admin/log-level", `{"level":"info"}`, 3, http.StatusOK},
+		{"invalid log level", http.MethodPut, "/api/admin/log-level", `{"level":"loud"}`, 3, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Authorization", "Bearer "+testAccessToken(t, "testsecret", tt.userID))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("got status %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestHandler_AdminAdjustBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockAdminService := service_mocks.NewMockAdminService(ctrl)
+	h := &Handler{adminService: mockAdminService}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success",
+			body: `{"amount":15,"reason":"late accrual","ticket_ref":"SUP-1"}`,
+			mockSetup: func() {
+				mockAdminService.EXPECT().AdjustBalance(gomock.Any(), int64(1), int64(10), models.BalanceAdjustmentRequest{Amount: 15, Reason: "late accrual", TicketRef: "SUP-1"}).
+					Return(&models.BalanceAdjustment{ID: 1}, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name: "missing reason",
+			body: `{"amount":15,"ticket_ref":"SUP-1"}`,
+			mockSetup: func() {
+				mockAdminService.EXPECT().AdjustBalance(gomock.Any(), int64(1), int64(10), gomock.Any()).Return(nil, apperrors.ErrInvalidAdjustment)
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "insufficient funds",
+			body: `{"amount":-1000,"reason":"chargeback","ticket_ref":"SUP-2"}`,
+			mockSetup: func() {
+				mockAdminService.EXPECT().AdjustBalance(gomock.Any(), int64(1), int64(10), gomock.Any()).Return(nil, apperrors.ErrInsufficientFunds)
+			},
+			wantStatusCode: http.StatusPaymentRequired,
+		},
+		{
+			name:           "invalid json",
+			body:           `{"amount":`,
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/users/10/balance/adjustments", bytes.NewBufferString(tt.body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "10")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, middleware.UserIDKey, int64(1))
+			w := httptest.NewRecorder()
+			h.AdminAdjustBalance(w, req.WithContext(ctx))
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantStatusCode)
+			}
+		})
+	}
+}
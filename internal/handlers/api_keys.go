@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	key, err := h.apiKeyService.Create(r.Context(), userID, req)
+	switch {
+	case err == nil:
+	case errors.Is(err, apperrors.ErrInvalidScope):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, apperrors.ErrInvalidRequest):
+		http.Error(w, "invalid API key name", http.StatusBadRequest)
+		return
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to create api key", zap.Error(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(key)
+}
+
+func (h *Handler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	keys, err := h.apiKeyService.List(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to list api keys", zap.Error(err))
+		return
+	}
+
+	if len(keys) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(keys); err != nil {
+		logger.FromContext(r.Context()).Error("failed to encode api keys json", zap.Error(err))
+	}
+}
+
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	keyID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid API key id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.apiKeyService.Revoke(r.Context(), userID, keyID)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, apperrors.ErrAPIKeyNotFound):
+		http.Error(w, "API key not found", http.StatusNotFound)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to revoke api key", zap.Error(err))
+	}
+}
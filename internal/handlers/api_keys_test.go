@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/mocks/service_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_CreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockAPIKeyService := service_mocks.NewMockAPIKeyService(ctrl)
+	h := &Handler{apiKeyService: mockAPIKeyService}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success",
+			body: `{"name":"partner","scopes":["orders:write"]}`,
+			mockSetup: func() {
+				mockAPIKeyService.EXPECT().Create(gomock.Any(), int64(1), models.CreateAPIKeyRequest{Name: "partner", Scopes: []string{"orders:write"}}).
+					Return(&models.CreateAPIKeyResponse{Key: "gm_key"}, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name: "invalid scope",
+			body: `{"name":"partner","scopes":["admin"]}`,
+			mockSetup: func() {
+				mockAPIKeyService.EXPECT().Create(gomock.Any(), int64(1), gomock.Any()).Return(nil, apperrors.ErrInvalidScope)
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid json",
+			body:           `{"name":`,
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "service error",
+			body: `{"name":"partner","scopes":["orders:write"]}`,
+			mockSetup: func() {
+				mockAPIKeyService.EXPECT().Create(gomock.Any(), int64(1), gomock.Any()).Return(nil, errors.New("fail"))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodPost, "/api/user/api-keys", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+			w := httptest.NewRecorder()
+			h.CreateAPIKey(w, req)
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			err := resp.Body.Close()
+			if err != nil {
+				return
+			}
+		})
+	}
+}
+
+func TestHandler_RevokeAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockAPIKeyService := service_mocks.NewMockAPIKeyService(ctrl)
+	h := &Handler{apiKeyService: mockAPIKeyService}
+
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success",
+			id:   "5",
+			mockSetup: func() {
+				mockAPIKeyService.EXPECT().Revoke(gomock.Any(), int64(1), int64(5)).Return(nil)
+			},
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name: "not found",
+			id:   "6",
+			mockSetup: func() {
+				mockAPIKeyService.EXPECT().Revoke(gomock.Any(), int64(1), int64(6)).Return(apperrors.ErrAPIKeyNotFound)
+			},
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:           "invalid id",
+			id:             "abc",
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodDelete, "/api/user/api-keys/"+tt.id, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, middleware.UserIDKey, int64(1))
+			w := httptest.NewRecorder()
+			h.RevokeAPIKey(w, req.WithContext(ctx))
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			err := resp.Body.Close()
+			if err != nil {
+				return
+			}
+		})
+	}
+}
+
+func TestRouter_APIKeyScopes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockAPIKeyService := service_mocks.NewMockAPIKeyService(ctrl)
+	mockOrderService := service_mocks.NewMockOrderService(ctrl)
+	h := &Handler{apiKeyService: mockAPIKeyService, orderService: mockOrderService}
+	router := NewRouter(h, "testsecret", middleware.NewUserRateLimiter(1000, 1000))
+
+	readOnlyKey := &models.APIKey{ID: 1, UserID: 3, Scopes: []string{models.ScopeOrdersRead}}
+	mockAPIKeyService.EXPECT().Authenticate(gomock.Any(), "gm_readonly").Return(readOnlyKey, nil).Times(3)
+	mockAPIKeyService.EXPECT().Authenticate(gomock.Any(), "gm_bad").Return(nil, apperrors.ErrInvalidAPIKey)
+	mockOrderService.EXPECT().GetUserOrders(gomock.Any(), int64(3)).Return(nil, nil)
+
+	tests := []struct {
+		method string
+		path   string
+		key    string
+		status int
+	}{
+		{http.MethodGet, "/api/user/orders", "gm_readonly", http.StatusNoContent},
+		{http.MethodPost, "/api/user/orders", "gm_readonly", http.StatusForbidden},
+		{http.MethodGet, "/api/user/balance", "gm_readonly", http.StatusForbidden},
+		{http.MethodGet, "/api/user/orders", "gm_bad", http.StatusUnauthorized},
+		{http.MethodGet, "/api/user/api-keys", "gm_readonly", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set(middleware.APIKeyHeader, tt.key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s %s: got %d, want %d", tt.method, tt.path, w.Code, tt.status)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"github.com/a2sh3r/gophermart/internal/apperrors"
 	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/models"
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
 	"time"
@@ -34,32 +36,23 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "user already exists", http.StatusConflict)
 			return
 		}
+		if errors.Is(err, apperrors.ErrWeakPassword) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 		http.Error(w, "internal server error", http.StatusInternalServerError)
-		logger.Log.Error("register failed", zap.Error(err))
+		logger.FromContext(r.Context()).Error("register failed", zap.Error(err))
 		return
 	}
 
 	user, err := h.userService.GetUserByLogin(r.Context(), req.Login)
 	if err != nil {
 		http.Error(w, "internal server error", http.StatusInternalServerError)
-		logger.Log.Error("get user failed", zap.Error(err))
-		return
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
-	})
-	tokenString, err := token.SignedString([]byte(h.secretKey))
-	if err != nil {
-		http.Error(w, "could not create token", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("get user failed", zap.Error(err))
 		return
 	}
 
-	w.Header().Set("Authorization", "Bearer "+tokenString)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(authResponse{Token: tokenString})
+	h.writeToken(w, user)
 }
 
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
@@ -78,13 +71,61 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	user, err := h.userService.GetUserByLogin(r.Context(), req.Login)
 	if err != nil {
 		http.Error(w, "internal server error", http.StatusInternalServerError)
-		logger.Log.Error("get user failed", zap.Error(err))
+		logger.FromContext(r.Context()).Error("get user failed", zap.Error(err))
+		return
+	}
+
+	if user.TOTPEnabled {
+		h.writeTwoFactorChallenge(w, user)
 		return
 	}
 
+	h.writeToken(w, user)
+}
+
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.CurrentPassword == "" || req.NewPassword == "" {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	err := h.userService.ChangePassword(r.Context(), userID, req.CurrentPassword, req.NewPassword)
+	switch {
+	case err == nil:
+	case errors.Is(err, apperrors.ErrInvalidCredentials):
+		http.Error(w, "invalid current password", http.StatusUnauthorized)
+		return
+	case errors.Is(err, apperrors.ErrWeakPassword), errors.Is(err, apperrors.ErrSamePassword):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("change password failed", zap.Error(err))
+		return
+	}
+
+	user, err := h.userService.GetUserByID(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("get user failed", zap.Error(err))
+		return
+	}
+
+	h.writeToken(w, user)
+}
+
+func (h *Handler) writeToken(w http.ResponseWriter, user *models.User) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+		"user_id":       user.ID,
+		"token_version": user.TokenVersion,
+		"exp":           time.Now().Add(24 * time.Hour).Unix(),
 	})
 	tokenString, err := token.SignedString([]byte(h.secretKey))
 	if err != nil {
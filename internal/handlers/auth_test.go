@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/middleware"
 	"github.com/a2sh3r/gophermart/internal/mocks/service_mocks"
 	"github.com/a2sh3r/gophermart/internal/models"
 	"github.com/golang/mock/gomock"
@@ -212,3 +214,88 @@ func TestHandler_Login(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockUserService := service_mocks.NewMockUserService(ctrl)
+	h := &Handler{userService: mockUserService, secretKey: "secret"}
+
+	tests := []struct {
+		name           string
+		body           string
+		userID         int64
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name:   "success",
+			body:   `{"current_password":"old-password","new_password":"new-password-1"}`,
+			userID: 1,
+			mockSetup: func() {
+				mockUserService.EXPECT().ChangePassword(gomock.Any(), int64(1), "old-password", "new-password-1").Return(nil)
+				mockUserService.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(&models.User{ID: 1, Login: "test", TokenVersion: 1}, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "wrong current password",
+			body:   `{"current_password":"wrong","new_password":"new-password-1"}`,
+			userID: 1,
+			mockSetup: func() {
+				mockUserService.EXPECT().ChangePassword(gomock.Any(), int64(1), "wrong", "new-password-1").Return(apperrors.ErrInvalidCredentials)
+			},
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "weak new password",
+			body:   `{"current_password":"old-password","new_password":"123"}`,
+			userID: 1,
+			mockSetup: func() {
+				mockUserService.EXPECT().ChangePassword(gomock.Any(), int64(1), "old-password", "123").Return(apperrors.ErrWeakPassword)
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "missing new password",
+			body:           `{"current_password":"old-password"}`,
+			userID:         1,
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "unauthorized",
+			body:           `{"current_password":"old-password","new_password":"new-password-1"}`,
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "service error",
+			body:   `{"current_password":"old-password","new_password":"new-password-1"}`,
+			userID: 1,
+			mockSetup: func() {
+				mockUserService.EXPECT().ChangePassword(gomock.Any(), int64(1), "old-password", "new-password-1").Return(errors.New("fail"))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodPut, "/api/user/password", bytes.NewBufferString(tt.body))
+			if tt.userID != 0 {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+			w := httptest.NewRecorder()
+			h.ChangePassword(w, req)
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			err := resp.Body.Close()
+			if err != nil {
+				return
+			}
+		})
+	}
+}
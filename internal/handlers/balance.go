@@ -3,12 +3,15 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/a2sh3r/gophermart/internal/apperrors"
 	"github.com/a2sh3r/gophermart/internal/logger"
 	"github.com/a2sh3r/gophermart/internal/middleware"
 	"github.com/a2sh3r/gophermart/internal/models"
 	"go.uber.org/zap"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
@@ -21,7 +24,7 @@ func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
 	balance, err := h.balanceService.GetUserBalance(r.Context(), userID)
 	if err != nil {
 		http.Error(w, "internal server error", http.StatusInternalServerError)
-		logger.Log.Error("failed to get user balance", zap.Error(err))
+		logger.FromContext(r.Context()).Error("failed to get user balance", zap.Error(err))
 		return
 	}
 
@@ -43,6 +46,11 @@ func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := h.twoFactorService.VerifyWithdrawal(r.Context(), userID, r.Header.Get(TOTPHeader)); err != nil {
+		h.writeTwoFactorError(w, r, err)
+		return
+	}
+
 	err := h.balanceService.Withdraw(r.Context(), userID, req)
 	switch {
 	case err == nil:
@@ -55,7 +63,7 @@ func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid withdrawal sum", http.StatusBadRequest)
 	default:
 		http.Error(w, "internal server error", http.StatusInternalServerError)
-		logger.Log.Error("withdraw error", zap.Error(err))
+		logger.FromContext(r.Context()).Error("withdraw error", zap.Error(err))
 	}
 }
 
@@ -66,10 +74,20 @@ func (h *Handler) GetWithdrawals(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filter, paginated, err := parseWithdrawalFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if paginated {
+		h.getWithdrawalsPage(w, r, userID, filter)
+		return
+	}
+
 	withdrawals, err := h.balanceService.GetWithdrawals(r.Context(), userID)
 	if err != nil {
 		http.Error(w, "internal server error", http.StatusInternalServerError)
-		logger.Log.Error("failed to get withdrawals", zap.Error(err))
+		logger.FromContext(r.Context()).Error("failed to get withdrawals", zap.Error(err))
 		return
 	}
 
@@ -82,6 +100,94 @@ func (h *Handler) GetWithdrawals(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 
 	if err := json.NewEncoder(w).Encode(withdrawals); err != nil {
-		logger.Log.Error("failed to encode withdrawals json", zap.Error(err))
+		logger.FromContext(r.Context()).Error("failed to encode withdrawals json", zap.Error(err))
+	}
+}
+
+func (h *Handler) getWithdrawalsPage(w http.ResponseWriter, r *http.Request, userID int64, filter models.WithdrawalFilter) {
+	page, err := h.balanceService.ListWithdrawals(r.Context(), userID, filter)
+	switch {
+	case err == nil:
+	case errors.Is(err, apperrors.ErrInvalidFilter), errors.Is(err, apperrors.ErrInvalidCursor), errors.Is(err, apperrors.ErrInvalidPageLimit):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to list withdrawals", zap.Error(err))
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(page.Totals.Count, 10))
+	w.Header().Set("X-Total-Sum", strconv.FormatFloat(page.Totals.Sum, 'f', 2, 64))
+	setNextPageHeaders(w, r, page.NextCursor)
+
+	if len(page.Withdrawals) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page.Withdrawals)
+}
+
+// parseWithdrawalFilter accepts the same limit, cursor, from and to parameters
+// as the orders listing plus sort=asc|desc.
+func parseWithdrawalFilter(r *http.Request) (filter models.WithdrawalFilter, paginated bool, err error) {
+	q := r.URL.Query()
+	for _, name := range []string{"limit", "cursor", "from", "to", "sort"} {
+		if q.Has(name) {
+			paginated = true
+		}
+	}
+	if !paginated {
+		return filter, false, nil
+	}
+
+	if filter.Limit, err = parseLimit(q.Get("limit")); err != nil {
+		return filter, true, err
+	}
+	filter.Cursor = q.Get("cursor")
+
+	if filter.From, err = parseTimeParam(q.Get("from"), "from"); err != nil {
+		return filter, true, err
+	}
+	if filter.To, err = parseTimeParam(q.Get("to"), "to"); err != nil {
+		return filter, true, err
+	}
+
+	switch strings.ToLower(q.Get("sort")) {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		return filter, true, fmt.Errorf("%w: sort must be asc or desc", apperrors.ErrInvalidFilter)
+	}
+
+	return filter, true, nil
+}
+
+func (h *Handler) GetBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	history, err := h.balanceService.GetHistory(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to get balance history", zap.Error(err))
+		return
+	}
+
+	if len(history) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(history); err != nil {
+		logger.FromContext(r.Context()).Error("failed to encode balance history json", zap.Error(err))
 	}
 }
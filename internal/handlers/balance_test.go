@@ -67,7 +67,9 @@ func TestHandler_Withdraw(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockBalanceService := service_mocks.NewMockBalanceService(ctrl)
-	h := &Handler{balanceService: mockBalanceService}
+	mockTwoFactorService := service_mocks.NewMockTwoFactorService(ctrl)
+	mockTwoFactorService.EXPECT().VerifyWithdrawal(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	h := &Handler{balanceService: mockBalanceService, twoFactorService: mockTwoFactorService}
 
 	tests := []struct {
 		name           string
@@ -202,3 +204,122 @@ func TestHandler_GetWithdrawals(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_GetBalanceHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBalanceService := service_mocks.NewMockBalanceService(ctrl)
+	h := &Handler{balanceService: mockBalanceService}
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success with adjustments",
+			mockSetup: func() {
+				history := []models.BalanceHistoryEntry{
+					{Type: models.HistoryEntryAdjustment, Reference: "SUP-1", Amount: 15, Description: "late accrual"},
+					{Type: models.HistoryEntryAccrual, Reference: "12345678903", Amount: 100},
+				}
+				mockBalanceService.EXPECT().GetHistory(gomock.Any(), int64(1)).Return(history, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "empty history",
+			mockSetup: func() {
+				mockBalanceService.EXPECT().GetHistory(gomock.Any(), int64(1)).Return(nil, nil)
+			},
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name: "service error",
+			mockSetup: func() {
+				mockBalanceService.EXPECT().GetHistory(gomock.Any(), int64(1)).Return(nil, errors.New("fail"))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodGet, "/api/user/balance/history", nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+			w := httptest.NewRecorder()
+			h.GetBalanceHistory(w, req)
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantStatusCode)
+			}
+		})
+	}
+}
+
+func TestHandler_GetWithdrawalsPaginated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBalanceService := service_mocks.NewMockBalanceService(ctrl)
+	h := &Handler{balanceService: mockBalanceService}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func()
+		wantStatusCode int
+		wantTotalSum   string
+	}{
+		{
+			name:  "page with totals",
+			query: "?limit=1&sort=asc",
+			mockSetup: func() {
+				page := &models.WithdrawalPage{
+					Withdrawals: []models.Withdrawal{{Order: "12345678903", Sum: 100.5}},
+					NextCursor:  "abc",
+					Totals:      models.WithdrawalTotals{Count: 3, Sum: 250.75},
+				}
+				mockBalanceService.EXPECT().ListWithdrawals(gomock.Any(), int64(1), models.WithdrawalFilter{Limit: 1, Ascending: true}).Return(page, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantTotalSum:   "250.75",
+		},
+		{
+			name:  "empty range",
+			query: "?from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z",
+			mockSetup: func() {
+				mockBalanceService.EXPECT().ListWithdrawals(gomock.Any(), int64(1), gomock.Any()).Return(&models.WithdrawalPage{}, nil)
+			},
+			wantStatusCode: http.StatusNoContent,
+			wantTotalSum:   "0.00",
+		},
+		{
+			name:           "invalid sort",
+			query:          "?sort=sideways",
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "invalid range",
+			query: "?from=2024-02-01T00:00:00Z&to=2024-01-01T00:00:00Z",
+			mockSetup: func() {
+				mockBalanceService.EXPECT().ListWithdrawals(gomock.Any(), int64(1), gomock.Any()).Return(nil, apperrors.ErrInvalidFilter)
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodGet, "/api/user/withdrawals"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+			w := httptest.NewRecorder()
+			h.GetWithdrawals(w, req)
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantStatusCode)
+			}
+			if got := w.Header().Get("X-Total-Sum"); got != tt.wantTotalSum {
+				t.Errorf("got X-Total-Sum %q, want %q", got, tt.wantTotalSum)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"github.com/a2sh3r/gophermart/internal/health"
+	"net/http"
+)
+
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, health.Report{Status: health.StatusOK})
+}
+
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	report := h.healthChecker.Run(r.Context())
+
+	status := http.StatusOK
+	if report.Status != health.StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/a2sh3r/gophermart/internal/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_Readyz(t *testing.T) {
+	tests := []struct {
+		name         string
+		checkErr     error
+		draining     bool
+		expectedCode int
+		expectedFail string
+	}{
+		{
+			name:         "ready",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "database down",
+			checkErr:     errors.New("connection refused"),
+			expectedCode: http.StatusServiceUnavailable,
+			expectedFail: "database",
+		},
+		{
+			name:         "draining",
+			draining:     true,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedFail: "shutdown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := health.NewChecker(0)
+			checker.Add("database", func(context.Context) error { return tt.checkErr })
+			if tt.draining {
+				checker.SetDraining()
+			}
+			h := &Handler{healthChecker: checker}
+
+			w := httptest.NewRecorder()
+			h.Readyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			resp := w.Result()
+			defer func() {
+				err := resp.Body.Close()
+				if err != nil {
+					return
+				}
+			}()
+
+			assert.Equal(t, tt.expectedCode, resp.StatusCode)
+
+			var report health.Report
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+			if tt.expectedFail == "" {
+				assert.Equal(t, health.StatusOK, report.Status)
+				return
+			}
+			assert.Equal(t, health.StatusFail, report.Status)
+			assert.Equal(t, health.StatusFail, report.Checks[tt.expectedFail].Status)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	filter, err := parseNotificationFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.notificationService.List(r.Context(), userID, filter)
+	switch {
+	case err == nil:
+	case errors.Is(err, apperrors.ErrInvalidCursor), errors.Is(err, apperrors.ErrInvalidPageLimit):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to list notifications", zap.Error(err))
+		return
+	}
+
+	w.Header().Set("X-Unread-Count", strconv.FormatInt(page.UnreadCount, 10))
+	setNextPageHeaders(w, r, page.NextCursor)
+
+	if len(page.Notifications) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page.Notifications)
+}
+
+func (h *Handler) GetUnreadNotificationsCount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	count, err := h.notificationService.UnreadCount(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to count unread notifications", zap.Error(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, models.UnreadCount{Unread: count})
+}
+
+func (h *Handler) MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	notificationID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || notificationID <= 0 {
+		http.Error(w, "invalid notification id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.notificationService.MarkRead(r.Context(), userID, notificationID)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, apperrors.ErrNotificationNotFound):
+		http.Error(w, "notification not found", http.StatusNotFound)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to mark notification read", zap.Error(err))
+	}
+}
+
+func (h *Handler) MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.notificationService.MarkAllRead(r.Context(), userID); err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to mark notifications read", zap.Error(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) GetNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	settings, err := h.notificationService.GetSettings(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to get notification settings", zap.Error(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, settings)
+}
+
+func (h *Handler) UpdateNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.NotificationSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := h.notificationService.UpdateSettings(r.Context(), userID, req)
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, settings)
+	case errors.Is(err, apperrors.ErrInvalidEmail):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to update notification settings", zap.Error(err))
+	}
+}
+
+func parseNotificationFilter(r *http.Request) (filter models.NotificationFilter, err error) {
+	q := r.URL.Query()
+
+	if filter.Limit, err = parseLimit(q.Get("limit")); err != nil {
+		return filter, err
+	}
+	filter.Cursor = q.Get("cursor")
+
+	if unread := q.Get("unread"); unread != "" {
+		filter.UnreadOnly, err = strconv.ParseBool(unread)
+		if err != nil {
+			return filter, fmt.Errorf("%w: unread must be true or false", apperrors.ErrInvalidFilter)
+		}
+	}
+
+	return filter, nil
+}
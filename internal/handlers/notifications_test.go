@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/mocks/service_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_GetNotifications(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockNotificationService := service_mocks.NewMockNotificationService(ctrl)
+	h := &Handler{notificationService: mockNotificationService}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func()
+		wantStatusCode int
+		wantUnread     string
+	}{
+		{
+			name:  "success",
+			query: "?unread=true&limit=10",
+			mockSetup: func() {
+				mockNotificationService.EXPECT().List(gomock.Any(), int64(1), models.NotificationFilter{UnreadOnly: true, Limit: 10}).
+					Return(&models.NotificationPage{Notifications: []models.Notification{{ID: 1}}, UnreadCount: 4, NextCursor: "abc"}, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantUnread:     "4",
+		},
+		{
+			name:  "empty inbox",
+			query: "",
+			mockSetup: func() {
+				mockNotificationService.EXPECT().List(gomock.Any(), int64(1), gomock.Any()).Return(&models.NotificationPage{}, nil)
+			},
+			wantStatusCode: http.StatusNoContent,
+			wantUnread:     "0",
+		},
+		{
+			name:           "invalid unread flag",
+			query:          "?unread=maybe",
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "invalid cursor",
+			query: "?cursor=bad",
+			mockSetup: func() {
+				mockNotificationService.EXPECT().List(gomock.Any(), int64(1), gomock.Any()).Return(nil, apperrors.ErrInvalidCursor)
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:  "service error",
+			query: "",
+			mockSetup: func() {
+				mockNotificationService.EXPECT().List(gomock.Any(), int64(1), gomock.Any()).Return(nil, errors.New("fail"))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodGet, "/api/user/notifications"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+			w := httptest.NewRecorder()
+			h.GetNotifications(w, req)
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			if got := resp.Header.Get("X-Unread-Count"); got != tt.wantUnread {
+				t.Errorf("got X-Unread-Count %q, want %q", got, tt.wantUnread)
+			}
+			err := resp.Body.Close()
+			if err != nil {
+				return
+			}
+		})
+	}
+}
+
+func TestHandler_MarkNotificationRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockNotificationService := service_mocks.NewMockNotificationService(ctrl)
+	h := &Handler{notificationService: mockNotificationService}
+
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success",
+			id:   "5",
+			mockSetup: func() {
+				mockNotificationService.EXPECT().MarkRead(gomock.Any(), int64(1), int64(5)).Return(nil)
+			},
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name: "not found",
+			id:   "6",
+			mockSetup: func() {
+				mockNotificationService.EXPECT().MarkRead(gomock.Any(), int64(1), int64(6)).Return(apperrors.ErrNotificationNotFound)
+			},
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:           "invalid id",
+			id:             "abc",
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodPost, "/api/user/notifications/"+tt.id+"/read", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, middleware.UserIDKey, int64(1))
+			w := httptest.NewRecorder()
+			h.MarkNotificationRead(w, req.WithContext(ctx))
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			err := resp.Body.Close()
+			if err != nil {
+				return
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateNotificationSettings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockNotificationService := service_mocks.NewMockNotificationService(ctrl)
+	h := &Handler{notificationService: mockNotificationService}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success",
+			body: `{"email":"user@example.com","email_enabled":true}`,
+			mockSetup: func() {
+				mockNotificationService.EXPECT().UpdateSettings(gomock.Any(), int64(1), models.NotificationSettings{Email: "user@example.com", EmailEnabled: true}).
+					Return(models.NotificationSettings{Email: "user@example.com", EmailEnabled: true}, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "invalid email",
+			body: `{"email":"nope","email_enabled":true}`,
+			mockSetup: func() {
+				mockNotificationService.EXPECT().UpdateSettings(gomock.Any(), int64(1), gomock.Any()).
+					Return(models.NotificationSettings{}, apperrors.ErrInvalidEmail)
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid json",
+			body:           `{"email":`,
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodPut, "/api/user/notifications/settings", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+			w := httptest.NewRecorder()
+			h.UpdateNotificationSettings(w, req)
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			err := resp.Body.Close()
+			if err != nil {
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	sseHeartbeatInterval = 15 * time.Second
+	sseRetryMillis       = 3000
+)
+
+// StreamOrderEvents serves order status changes and balance credits as
+// server-sent events. Clients resume after a reconnect by sending the last
+// received id in the Last-Event-ID header; without it only new events are
+// streamed.
+func (h *Handler) StreamOrderEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := r.Context()
+
+	sub := h.orderEvents.Subscribe(userID)
+	defer sub.Close()
+
+	lastID, err := h.resumeEventID(r, userID)
+	if err != nil {
+		http.Error(w, "invalid Last-Event-ID", http.StatusBadRequest)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetryMillis); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		logger.FromContext(r.Context()).Error("streaming is not supported by response writer", zap.Error(err))
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	// Deliver anything recorded between the resume point and the subscription.
+	wake := true
+	for {
+		if wake {
+			if lastID, err = h.writePendingEvents(w, r, userID, lastID); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+			wake = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.streamsDone:
+			return
+		case <-sub.C:
+			wake = true
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *Handler) resumeEventID(r *http.Request, userID int64) (int64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return h.orderEvents.LatestID(r.Context(), userID)
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("invalid event id %q", value)
+	}
+	return id, nil
+}
+
+func (h *Handler) writePendingEvents(w http.ResponseWriter, r *http.Request, userID, lastID int64) (int64, error) {
+	for {
+		events, err := h.orderEvents.Since(r.Context(), userID, lastID)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("failed to load order events", zap.Int64("userID", userID), zap.Error(err))
+			return lastID, err
+		}
+
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return lastID, err
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return lastID, err
+			}
+			lastID = event.ID
+		}
+
+		if len(events) == 0 {
+			return lastID, nil
+		}
+	}
+}
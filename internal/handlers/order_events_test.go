@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"context"
+	"github.com/a2sh3r/gophermart/internal/events"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/mocks/service_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_StreamOrderEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockEvents := service_mocks.NewMockOrderEventService(ctrl)
+	h := &Handler{orderEvents: mockEvents}
+	broker := events.NewBroker()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	accrualSum := 100.0
+	pending := []models.OrderEvent{
+		{ID: 5, Type: models.OrderEventStatusChanged, OrderNumber: "12345678903", Status: "PROCESSED", Accrual: &accrualSum},
+		{ID: 6, Type: models.OrderEventBalanceCredited, OrderNumber: "12345678903", Accrual: &accrualSum},
+	}
+
+	mockEvents.EXPECT().Subscribe(int64(1)).Return(broker.Subscribe(1))
+	gomock.InOrder(
+		mockEvents.EXPECT().Since(gomock.Any(), int64(1), int64(4)).Return(pending, nil),
+		mockEvents.EXPECT().Since(gomock.Any(), int64(1), int64(6)).DoAndReturn(
+			func(context.Context, int64, int64) ([]models.OrderEvent, error) {
+				cancel()
+				return nil, nil
+			}),
+	)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/orders/events", nil)
+	req.Header.Set("Last-Event-ID", "4")
+	req = req.WithContext(context.WithValue(ctx, middleware.UserIDKey, int64(1)))
+	w := httptest.NewRecorder()
+	h.StreamOrderEvents(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+	if got := w.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("got Content-Type %q", got)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		"retry: 3000\n\n",
+		"id: 5\nevent: order.status_changed\ndata: {\"id\":5,",
+		"id: 6\nevent: balance.credited\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("stream does not contain %q:\n%s", want, body)
+		}
+	}
+	if broker.SubscriberCount(1) != 0 {
+		t.Error("subscription must be closed when the client disconnects")
+	}
+}
+
+func TestHandler_StreamOrderEventsCloseStreams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockEvents := service_mocks.NewMockOrderEventService(ctrl)
+	h := &Handler{orderEvents: mockEvents, streamsDone: make(chan struct{})}
+	broker := events.NewBroker()
+
+	mockEvents.EXPECT().Subscribe(int64(1)).Return(broker.Subscribe(1))
+	mockEvents.EXPECT().Since(gomock.Any(), int64(1), int64(4)).DoAndReturn(
+		func(context.Context, int64, int64) ([]models.OrderEvent, error) {
+			h.CloseStreams()
+			return nil, nil
+		})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/orders/events", nil)
+	req.Header.Set("Last-Event-ID", "4")
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		h.StreamOrderEvents(w, req)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream was not closed on shutdown")
+	}
+	if broker.SubscriberCount(1) != 0 {
+		t.Error("subscription must be closed on shutdown")
+	}
+}
+
+func TestHandler_StreamOrderEventsStartsAtLatest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockEvents := service_mocks.NewMockOrderEventService(ctrl)
+	h := &Handler{orderEvents: mockEvents}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockEvents.EXPECT().Subscribe(int64(1)).Return(events.NewBroker().Subscribe(1))
+	mockEvents.EXPECT().LatestID(gomock.Any(), int64(1)).Return(int64(42), nil)
+	mockEvents.EXPECT().Since(gomock.Any(), int64(1), int64(42)).DoAndReturn(
+		func(context.Context, int64, int64) ([]models.OrderEvent, error) {
+			cancel()
+			return nil, nil
+		})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/orders/events", nil)
+	req = req.WithContext(context.WithValue(ctx, middleware.UserIDKey, int64(1)))
+	w := httptest.NewRecorder()
+	h.StreamOrderEvents(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestHandler_StreamOrderEventsInvalidLastEventID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockEvents := service_mocks.NewMockOrderEventService(ctrl)
+	h := &Handler{orderEvents: mockEvents}
+
+	mockEvents.EXPECT().Subscribe(int64(1)).Return(events.NewBroker().Subscribe(1))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/orders/events", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+	w := httptest.NewRecorder()
+	h.StreamOrderEvents(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
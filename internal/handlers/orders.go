@@ -3,17 +3,24 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/a2sh3r/gophermart/internal/apperrors"
 	"github.com/a2sh3r/gophermart/internal/logger"
 	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/models"
 	"github.com/a2sh3r/gophermart/internal/utils"
+	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 	"io"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
+const maxBatchBodySize = 1 << 20
+
 func (h *Handler) UploadOrder(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
@@ -51,6 +58,67 @@ func (h *Handler) UploadOrder(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *Handler) UploadOrdersBatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	numbers, err := parseOrderNumbers(w, r)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		http.Error(w, apperrors.ErrBatchTooLarge.Error(), http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.orderService.UploadOrders(r.Context(), numbers, userID)
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, results)
+	case errors.Is(err, apperrors.ErrEmptyBatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, apperrors.ErrBatchTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to upload order batch", zap.Error(err))
+	}
+}
+
+// parseOrderNumbers accepts either a JSON array of strings or plain text with
+// one order number per line. A body over maxBatchBodySize fails with
+// *http.MaxBytesError instead of being cut off mid-number.
+func parseOrderNumbers(w http.ResponseWriter, r *http.Request) ([]string, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchBodySize))
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		var numbers []string
+		if err := json.Unmarshal(body, &numbers); err != nil {
+			return nil, err
+		}
+		for i := range numbers {
+			numbers[i] = strings.TrimSpace(numbers[i])
+		}
+		return numbers, nil
+	}
+
+	var numbers []string
+	for _, line := range strings.Split(string(body), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			numbers = append(numbers, line)
+		}
+	}
+	return numbers, nil
+}
+
 func (h *Handler) GetOrders(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
@@ -58,6 +126,16 @@ func (h *Handler) GetOrders(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	filter, paginated, err := parseOrderFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if paginated {
+		h.getOrdersPage(w, r, userID, filter)
+		return
+	}
+
 	orders, err := h.orderService.GetUserOrders(r.Context(), userID)
 	if err != nil {
 		http.Error(w, "internal server error", http.StatusInternalServerError)
@@ -72,6 +150,129 @@ func (h *Handler) GetOrders(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 
 	if err := json.NewEncoder(w).Encode(orders); err != nil {
-		logger.Log.Error("failed to encode orders json", zap.Error(err))
+		logger.FromContext(r.Context()).Error("failed to encode orders json", zap.Error(err))
+	}
+}
+
+func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	number := chi.URLParam(r, "number")
+	if !regexp.MustCompile(`^\d+$`).MatchString(number) {
+		http.Error(w, "invalid order format", http.StatusBadRequest)
+		return
+	}
+
+	order, err := h.orderService.GetUserOrder(r.Context(), userID, number)
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, order)
+	case errors.Is(err, apperrors.ErrOrderNotFound):
+		http.Error(w, "order not found", http.StatusNotFound)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to get order", zap.String("order", number), zap.Error(err))
+	}
+}
+
+func (h *Handler) getOrdersPage(w http.ResponseWriter, r *http.Request, userID int64, filter models.OrderFilter) {
+	page, err := h.orderService.ListUserOrders(r.Context(), userID, filter)
+	switch {
+	case err == nil:
+	case errors.Is(err, apperrors.ErrInvalidFilter), errors.Is(err, apperrors.ErrInvalidCursor), errors.Is(err, apperrors.ErrInvalidPageLimit):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to list orders", zap.Error(err))
+		return
+	}
+
+	setNextPageHeaders(w, r, page.NextCursor)
+
+	if len(page.Orders) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page.Orders)
+}
+
+// parseOrderFilter reads the optional pagination and filter query parameters.
+// It reports paginated=false when none of them are present so that callers can
+// keep returning the full list.
+func parseOrderFilter(r *http.Request) (filter models.OrderFilter, paginated bool, err error) {
+	q := r.URL.Query()
+	for _, name := range []string{"limit", "cursor", "status", "from", "to"} {
+		if q.Has(name) {
+			paginated = true
+		}
+	}
+	if !paginated {
+		return filter, false, nil
 	}
+
+	if filter.Limit, err = parseLimit(q.Get("limit")); err != nil {
+		return filter, true, err
+	}
+	filter.Cursor = q.Get("cursor")
+
+	for _, value := range q["status"] {
+		for _, status := range strings.Split(value, ",") {
+			if status = strings.ToUpper(strings.TrimSpace(status)); status != "" {
+				filter.Statuses = append(filter.Statuses, status)
+			}
+		}
+	}
+
+	if filter.From, err = parseTimeParam(q.Get("from"), "from"); err != nil {
+		return filter, true, err
+	}
+	if filter.To, err = parseTimeParam(q.Get("to"), "to"); err != nil {
+		return filter, true, err
+	}
+
+	return filter, true, nil
+}
+
+func parseLimit(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit <= 0 {
+		return 0, apperrors.ErrInvalidPageLimit
+	}
+	return limit, nil
+}
+
+func parseTimeParam(value, name string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be an RFC 3339 timestamp", apperrors.ErrInvalidFilter, name)
+	}
+	return &t, nil
+}
+
+// setNextPageHeaders advertises the next page both as an opaque cursor and as
+// an RFC 8288 Link built from the current request URL.
+func setNextPageHeaders(w http.ResponseWriter, r *http.Request, cursor string) {
+	if cursor == "" {
+		return
+	}
+
+	next := *r.URL
+	q := next.Query()
+	q.Set("cursor", cursor)
+	next.RawQuery = q.Encode()
+
+	w.Header().Set("X-Next-Cursor", cursor)
+	w.Header().Set("Link", "<"+next.RequestURI()+`>; rel="next"`)
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/a2sh3r/gophermart/internal/middleware"
 	"github.com/a2sh3r/gophermart/internal/mocks/service_mocks"
 	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"net/http"
 	"net/http/httptest"
@@ -172,3 +173,210 @@ func TestHandler_GetOrders(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_GetOrdersPaginated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOrderService := service_mocks.NewMockOrderService(ctrl)
+	h := &Handler{orderService: mockOrderService}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func()
+		wantStatusCode int
+		wantLink       string
+	}{
+		{
+			name:  "first page with next cursor",
+			query: "?limit=1&status=new,processed",
+			mockSetup: func() {
+				filter := models.OrderFilter{Limit: 1, Statuses: []string{"NEW", "PROCESSED"}}
+				page := &models.OrderPage{Orders: []models.Order{{Number: "12345678903"}}, NextCursor: "abc"}
+				mockOrderService.EXPECT().ListUserOrders(gomock.Any(), int64(1), filter).Return(page, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantLink:       `</api/user/orders?cursor=abc&limit=1&status=new%2Cprocessed>; rel="next"`,
+		},
+		{
+			name:  "empty page",
+			query: "?cursor=abc",
+			mockSetup: func() {
+				mockOrderService.EXPECT().ListUserOrders(gomock.Any(), int64(1), models.OrderFilter{Cursor: "abc"}).Return(&models.OrderPage{}, nil)
+			},
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name:  "invalid cursor",
+			query: "?cursor=bad",
+			mockSetup: func() {
+				mockOrderService.EXPECT().ListUserOrders(gomock.Any(), int64(1), gomock.Any()).Return(nil, apperrors.ErrInvalidCursor)
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid limit",
+			query:          "?limit=abc",
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid from",
+			query:          "?from=yesterday",
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodGet, "/api/user/orders"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+			w := httptest.NewRecorder()
+			h.GetOrders(w, req)
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantStatusCode)
+			}
+			if got := w.Header().Get("Link"); got != tt.wantLink {
+				t.Errorf("got Link %q, want %q", got, tt.wantLink)
+			}
+		})
+	}
+}
+
+func TestHandler_GetOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOrderService := service_mocks.NewMockOrderService(ctrl)
+	h := &Handler{orderService: mockOrderService}
+
+	tests := []struct {
+		name           string
+		number         string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name:   "success",
+			number: "12345678903",
+			mockSetup: func() {
+				detail := &models.OrderDetail{
+					Order:   models.Order{Number: "12345678903", Status: "PROCESSED"},
+					History: []models.OrderStatusChange{{Status: "NEW"}, {Status: "PROCESSED"}},
+				}
+				mockOrderService.EXPECT().GetUserOrder(gomock.Any(), int64(1), "12345678903").Return(detail, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:   "not found",
+			number: "12345678903",
+			mockSetup: func() {
+				mockOrderService.EXPECT().GetUserOrder(gomock.Any(), int64(1), "12345678903").Return(nil, apperrors.ErrOrderNotFound)
+			},
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:           "invalid number",
+			number:         "abc",
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodGet, "/api/user/orders/"+tt.number, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("number", tt.number)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, middleware.UserIDKey, int64(1))
+			w := httptest.NewRecorder()
+			h.GetOrder(w, req.WithContext(ctx))
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantStatusCode)
+			}
+		})
+	}
+}
+
+func TestHandler_UploadOrdersBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOrderService := service_mocks.NewMockOrderService(ctrl)
+	h := &Handler{orderService: mockOrderService}
+
+	results := []models.BatchOrderResult{{Number: "12345678903", Status: models.BatchOrderAccepted}}
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name:        "json array",
+			contentType: "application/json",
+			body:        `["12345678903", " 79927398713 "]`,
+			mockSetup: func() {
+				mockOrderService.EXPECT().UploadOrders(gomock.Any(), []string{"12345678903", "79927398713"}, int64(1)).Return(results, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:        "newline separated text",
+			contentType: "text/plain",
+			body:        "12345678903\r\n\n79927398713\n",
+			mockSetup: func() {
+				mockOrderService.EXPECT().UploadOrders(gomock.Any(), []string{"12345678903", "79927398713"}, int64(1)).Return(results, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "malformed json",
+			contentType:    "application/json",
+			body:           `["12345678903"`,
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:        "too large",
+			contentType: "text/plain",
+			body:        "12345678903",
+			mockSetup: func() {
+				mockOrderService.EXPECT().UploadOrders(gomock.Any(), gomock.Any(), int64(1)).Return(nil, apperrors.ErrBatchTooLarge)
+			},
+			wantStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "body over limit",
+			contentType:    "text/plain",
+			body:           strings.Repeat("12345678903\n", maxBatchBodySize/12+1),
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "empty batch",
+			contentType: "text/plain",
+			body:        "",
+			mockSetup: func() {
+				mockOrderService.EXPECT().UploadOrders(gomock.Any(), gomock.Nil(), int64(1)).Return(nil, apperrors.ErrEmptyBatch)
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodPost, "/api/user/orders/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+			w := httptest.NewRecorder()
+			h.UploadOrdersBatch(w, req)
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantStatusCode)
+			}
+		})
+	}
+}
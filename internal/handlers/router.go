@@ -1,39 +1,82 @@
 package handlers
 
 import (
+	"github.com/a2sh3r/gophermart/internal/health"
+	"github.com/a2sh3r/gophermart/internal/metrics"
 	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/models"
 	"github.com/a2sh3r/gophermart/internal/service"
 	"github.com/go-chi/chi/v5"
 	"net/http"
+	"sync"
 )
 
 type Handler struct {
-	userService    service.UserService
-	orderService   service.OrderService
-	balanceService service.BalanceService
-	secretKey      string
+	userService         service.UserService
+	orderService        service.OrderService
+	balanceService      service.BalanceService
+	twoFactorService    service.TwoFactorService
+	apiKeyService       service.APIKeyService
+	adminService        service.AdminService
+	accountService      service.AccountService
+	orderEvents         service.OrderEventService
+	webhookService      service.WebhookService
+	notificationService service.NotificationService
+	healthChecker       *health.Checker
+	secretKey           string
+	streamsDone         chan struct{}
+	closeStreams        sync.Once
 }
 
 func NewHandler(
 	userService service.UserService,
 	orderService service.OrderService,
 	balanceService service.BalanceService,
+	twoFactorService service.TwoFactorService,
+	apiKeyService service.APIKeyService,
+	adminService service.AdminService,
+	accountService service.AccountService,
+	orderEvents service.OrderEventService,
+	webhookService service.WebhookService,
+	notificationService service.NotificationService,
+	healthChecker *health.Checker,
 	secretKey string,
 ) *Handler {
 	return &Handler{
-		userService:    userService,
-		orderService:   orderService,
-		balanceService: balanceService,
-		secretKey:      secretKey,
+		userService:         userService,
+		orderService:        orderService,
+		balanceService:      balanceService,
+		twoFactorService:    twoFactorService,
+		apiKeyService:       apiKeyService,
+		adminService:        adminService,
+		accountService:      accountService,
+		orderEvents:         orderEvents,
+		webhookService:      webhookService,
+		notificationService: notificationService,
+		healthChecker:       healthChecker,
+		secretKey:           secretKey,
+		streamsDone:         make(chan struct{}),
 	}
 }
 
-func NewRouter(handler *Handler, secretKey string) chi.Router {
-	r := chi.NewRouter()
+// CloseStreams ends every open event stream. http.Server.Shutdown does not
+// cancel request contexts, so without it long-lived streams would hold the
+// shutdown until its timeout. Clients reconnect and resume by event id.
+func (h *Handler) CloseStreams() {
+	h.closeStreams.Do(func() {
+		if h.streamsDone != nil {
+			close(h.streamsDone)
+		}
+	})
+}
 
-	limiter := middleware.NewUserRateLimiter(1000, 1000)
+func NewRouter(handler *Handler, secretKey string, limiter *middleware.UserLimiter) chi.Router {
+	r := chi.NewRouter()
 
+	r.Use(middleware.NewTracingMiddleware())
+	r.Use(middleware.NewRequestIDMiddleware())
 	r.Use(middleware.NewLoggingMiddleware())
+	r.Use(middleware.NewMetricsMiddleware())
 	r.Use(middleware.NewGzipMiddleware())
 	r.Use(middleware.NewHashMiddleware(secretKey))
 	r.Use(middleware.RateLimitMiddleware(limiter))
@@ -45,18 +88,79 @@ func NewRouter(handler *Handler, secretKey string) chi.Router {
 		http.Error(w, "Invalid URL format", http.StatusNotFound)
 	})
 
+	r.Handle("/metrics", metrics.Handler())
+	r.Get("/healthz", handler.Healthz)
+	r.Get("/readyz", handler.Readyz)
+
 	r.Route("/api/user", func(r chi.Router) {
 		r.Post("/register", handler.Register)
 		r.Post("/login", handler.Login)
+		r.Post("/login/2fa", handler.LoginTwoFactor)
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.AuthMiddleware(secretKey, handler.userService, handler.apiKeyService))
+
+			r.With(middleware.RequireScope(models.ScopeOrdersWrite)).Post("/orders", handler.UploadOrder)
+			r.With(middleware.RequireScope(models.ScopeOrdersWrite)).Post("/orders/batch", handler.UploadOrdersBatch)
+			r.With(middleware.RequireScope(models.ScopeOrdersRead)).Get("/orders", handler.GetOrders)
+			r.With(middleware.RequireScope(models.ScopeOrdersRead)).Get("/orders/events", handler.StreamOrderEvents)
+			r.With(middleware.RequireScope(models.ScopeOrdersRead)).Get("/orders/{number}", handler.GetOrder)
+			r.With(middleware.RequireScope(models.ScopeBalanceRead)).Get("/balance", handler.GetBalance)
+			r.With(middleware.RequireScope(models.ScopeBalanceRead)).Get("/balance/history", handler.GetBalanceHistory)
+			r.With(middleware.RequireScope(models.ScopeBalanceWrite)).Post("/balance/withdraw", handler.Withdraw)
+			r.With(middleware.RequireScope(models.ScopeBalanceRead)).Get("/withdrawals", handler.GetWithdrawals)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.JWTMiddleware(secretKey, handler.userService))
+
+			r.Put("/password", handler.ChangePassword)
+			r.Get("/export", handler.ExportUserData)
+			r.Delete("/", handler.DeleteAccount)
+
+			r.Post("/2fa/setup", handler.SetupTwoFactor)
+			r.Post("/2fa/enable", handler.EnableTwoFactor)
+			r.Post("/2fa/disable", handler.DisableTwoFactor)
+			r.Post("/2fa/recovery-codes", handler.RegenerateRecoveryCodes)
+
+			r.Post("/api-keys", handler.CreateAPIKey)
+			r.Get("/api-keys", handler.GetAPIKeys)
+			r.Delete("/api-keys/{id}", handler.RevokeAPIKey)
+
+			r.Post("/webhooks", handler.CreateWebhook)
+			r.Get("/webhooks", handler.GetWebhooks)
+			r.Delete("/webhooks/{id}", handler.DeleteWebhook)
+			r.Get("/webhooks/{id}/deliveries", handler.GetWebhookDeliveries)
+
+			r.Get("/notifications", handler.GetNotifications)
+			r.Get("/notifications/unread-count", handler.GetUnreadNotificationsCount)
+			r.Post("/notifications/read-all", handler.MarkAllNotificationsRead)
+			r.Post("/notifications/{id}/read", handler.MarkNotificationRead)
+			r.Get("/notifications/settings", handler.GetNotificationSettings)
+			r.Put("/notifications/settings", handler.UpdateNotificationSettings)
+		})
+	})
+
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(middleware.JWTMiddleware(secretKey, handler.userService))
+		r.Use(middleware.RequireRole(handler.userService, models.RoleSupport))
+
+		r.Get("/users", handler.AdminFindUser)
+		r.Get("/users/{id}", handler.AdminGetUser)
+		r.Get("/users/{id}/orders", handler.AdminGetUserOrders)
+		r.Get("/users/{id}/balance", handler.AdminGetUserBalance)
+		r.Get("/users/{id}/withdrawals", handler.AdminGetUserWithdrawals)
+		r.Get("/users/{id}/balance/adjustments", handler.AdminGetAdjustments)
+		r.Get("/users/{id}/webhooks", handler.AdminGetWebhooks)
+		r.Get("/log-level", handler.AdminGetLogLevel)
 
 		r.Group(func(r chi.Router) {
-			r.Use(middleware.JWTMiddleware(secretKey))
+			r.Use(middleware.RequireRole(handler.userService, models.RoleAdmin))
 
-			r.Post("/orders", handler.UploadOrder)
-			r.Get("/orders", handler.GetOrders)
-			r.Get("/balance", handler.GetBalance)
-			r.Post("/balance/withdraw", handler.Withdraw)
-			r.Get("/withdrawals", handler.GetWithdrawals)
+			r.Put("/users/{id}/role", handler.AdminSetUserRole)
+			r.Post("/users/{id}/balance/adjustments", handler.AdminAdjustBalance)
+			r.Post("/users/{id}/webhooks", handler.AdminCreateWebhook)
+			r.Put("/log-level", handler.AdminSetLogLevel)
 		})
 	})
 
@@ -1,16 +1,20 @@
 package handlers
 
 import (
+	"github.com/a2sh3r/gophermart/internal/health"
+	"github.com/a2sh3r/gophermart/internal/middleware"
 	"github.com/a2sh3r/gophermart/internal/mocks/service_mocks"
 	"github.com/golang/mock/gomock"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
 func TestRouter_Routes(t *testing.T) {
 	handler := &Handler{}
-	router := NewRouter(handler, "testsecret")
+	router := NewRouter(handler, "testsecret", middleware.NewUserRateLimiter(1000, 1000))
 
 	tests := []struct {
 		method string
@@ -21,6 +25,14 @@ func TestRouter_Routes(t *testing.T) {
 		{"POST", "/api/user/register", http.StatusBadRequest},
 		{"POST", "/api/user/login", http.StatusBadRequest},
 		{"GET", "/notfound", http.StatusNotFound},
+		{"GET", "/api/admin/users/1", http.StatusUnauthorized},
+		{"GET", "/api/user/export", http.StatusUnauthorized},
+		{"DELETE", "/api/user", http.StatusUnauthorized},
+		{"GET", "/api/user/webhooks", http.StatusUnauthorized},
+		{"POST", "/api/admin/users/1/webhooks", http.StatusUnauthorized},
+		{"GET", "/api/user/notifications", http.StatusUnauthorized},
+		{"GET", "/metrics", http.StatusOK},
+		{"GET", "/healthz", http.StatusOK},
 	}
 
 	for _, tt := range tests {
@@ -38,6 +50,76 @@ func TestRouter_Routes(t *testing.T) {
 	}
 }
 
+func TestRouter_Metrics(t *testing.T) {
+	router := NewRouter(&Handler{}, "testsecret", middleware.NewUserRateLimiter(1000, 1000))
+
+	for _, path := range []string{"/api/user/orders/12345678903", "/no/such/path"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	resp := w.Result()
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			return
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`gophermart_http_requests_total{method="GET",route="/api/user/orders/{number}",status="401"}`,
+		`gophermart_http_requests_total{method="GET",route="unmatched",status="404"}`,
+		`gophermart_http_request_duration_seconds_count{method="GET",route="/api/user/orders/{number}",status="401"}`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics output does not contain %s", want)
+		}
+	}
+}
+
+func TestRouter_RequestID(t *testing.T) {
+	router := NewRouter(&Handler{}, "testsecret", middleware.NewUserRateLimiter(1000, 1000))
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "generated when missing"},
+		{name: "caller id is kept", incoming: "edge-4f1c9a", keep: true},
+		{name: "invalid caller id is replaced", incoming: "bad id\r\nX-Injected: 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+			if tt.incoming != "" {
+				req.Header.Set(middleware.RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			got := w.Header().Get(middleware.RequestIDHeader)
+			if tt.keep {
+				if got != tt.incoming {
+					t.Errorf("got request id %q, want %q", got, tt.incoming)
+				}
+				return
+			}
+			if len(got) != 32 {
+				t.Errorf("got request id %q, want a generated one", got)
+			}
+		})
+	}
+}
+
 func TestNewHandler(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -45,8 +127,15 @@ func TestNewHandler(t *testing.T) {
 	mockUserService := service_mocks.NewMockUserService(ctrl)
 	mockOrderService := service_mocks.NewMockOrderService(ctrl)
 	mockBalanceService := service_mocks.NewMockBalanceService(ctrl)
+	mockTwoFactorService := service_mocks.NewMockTwoFactorService(ctrl)
+	mockAPIKeyService := service_mocks.NewMockAPIKeyService(ctrl)
+	mockAdminService := service_mocks.NewMockAdminService(ctrl)
+	mockAccountService := service_mocks.NewMockAccountService(ctrl)
+	mockOrderEventService := service_mocks.NewMockOrderEventService(ctrl)
+	mockWebhookService := service_mocks.NewMockWebhookService(ctrl)
+	mockNotificationService := service_mocks.NewMockNotificationService(ctrl)
 
-	h := NewHandler(mockUserService, mockOrderService, mockBalanceService, "test-secret")
+	h := NewHandler(mockUserService, mockOrderService, mockBalanceService, mockTwoFactorService, mockAPIKeyService, mockAdminService, mockAccountService, mockOrderEventService, mockWebhookService, mockNotificationService, health.NewChecker(0), "test-secret")
 
 	if h == nil {
 		t.Fatal("NewHandler returned nil")
@@ -61,4 +150,28 @@ func TestNewHandler(t *testing.T) {
 	if h.balanceService == nil {
 		t.Error("balanceService is nil")
 	}
+	if h.twoFactorService == nil {
+		t.Error("twoFactorService is nil")
+	}
+	if h.apiKeyService == nil {
+		t.Error("apiKeyService is nil")
+	}
+	if h.adminService == nil {
+		t.Error("adminService is nil")
+	}
+	if h.accountService == nil {
+		t.Error("accountService is nil")
+	}
+	if h.orderEvents == nil {
+		t.Error("orderEvents is nil")
+	}
+	if h.webhookService == nil {
+		t.Error("webhookService is nil")
+	}
+	if h.notificationService == nil {
+		t.Error("notificationService is nil")
+	}
+	if h.healthChecker == nil {
+		t.Error("healthChecker is nil")
+	}
 }
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+const (
+	TOTPHeader             = "X-TOTP-Code"
+	twoFactorChallengeTTL  = 5 * time.Minute
+	twoFactorChallengeType = "2fa_challenge"
+)
+
+func (h *Handler) SetupTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	setup, err := h.twoFactorService.Setup(r.Context(), userID)
+	if err != nil {
+		h.writeTwoFactorError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(setup)
+}
+
+func (h *Handler) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.TOTPCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.twoFactorService.Enable(r.Context(), userID, req.Code)
+	if err != nil {
+		h.writeTwoFactorError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(models.RecoveryCodes{Codes: codes})
+}
+
+func (h *Handler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.TOTPCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.twoFactorService.Disable(r.Context(), userID, req.Code); err != nil {
+		h.writeTwoFactorError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.TOTPCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		h.writeTwoFactorError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(models.RecoveryCodes{Codes: codes})
+}
+
+func (h *Handler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req models.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChallengeToken == "" || req.Code == "" {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := h.parseTwoFactorChallenge(req.ChallengeToken)
+	if err != nil {
+		http.Error(w, "invalid or expired challenge", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.twoFactorService.Verify(r.Context(), userID, req.Code); err != nil {
+		h.writeTwoFactorError(w, r, err)
+		return
+	}
+
+	user, err := h.userService.GetUserByID(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("get user failed", zap.Error(err))
+		return
+	}
+
+	h.writeToken(w, user)
+}
+
+func (h *Handler) writeTwoFactorChallenge(w http.ResponseWriter, user *models.User) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": user.ID,
+		"purpose": twoFactorChallengeType,
+		"exp":     time.Now().Add(twoFactorChallengeTTL).Unix(),
+	})
+	tokenString, err := token.SignedString(h.twoFactorChallengeKey())
+	if err != nil {
+		http.Error(w, "could not create token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(models.TwoFactorChallenge{
+		TwoFactorRequired: true,
+		ChallengeToken:    tokenString,
+	})
+}
+
+func (h *Handler) parseTwoFactorChallenge(tokenString string) (int64, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return h.twoFactorChallengeKey(), nil
+	})
+	if err != nil || !token.Valid {
+		return 0, apperrors.ErrInvalidChallenge
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != twoFactorChallengeType {
+		return 0, apperrors.ErrInvalidChallenge
+	}
+
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, apperrors.ErrInvalidChallenge
+	}
+
+	return int64(userID), nil
+}
+
+// twoFactorChallengeKey derives a separate signing key so that a challenge
+// token can never be accepted by JWTMiddleware as an access token.
+func (h *Handler) twoFactorChallengeKey() []byte {
+	return []byte(h.secretKey + ":" + twoFactorChallengeType)
+}
+
+func (h *Handler) writeTwoFactorError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrTOTPRequired):
+		http.Error(w, "two-factor code required", http.StatusUnauthorized)
+	case errors.Is(err, apperrors.ErrInvalidTOTPCode):
+		http.Error(w, "invalid two-factor code", http.StatusUnauthorized)
+	case errors.Is(err, apperrors.ErrTOTPLocked):
+		http.Error(w, "too many invalid two-factor codes, try again later", http.StatusTooManyRequests)
+	case errors.Is(err, apperrors.ErrTOTPNotConfigured):
+		http.Error(w, "two-factor authentication is not configured", http.StatusConflict)
+	case errors.Is(err, apperrors.ErrTOTPAlreadyEnabled):
+		http.Error(w, "two-factor authentication is already enabled", http.StatusConflict)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("two-factor request failed", zap.Error(err))
+	}
+}
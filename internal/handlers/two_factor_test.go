@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/mocks/service_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_LoginWithTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockUserService := service_mocks.NewMockUserService(ctrl)
+	mockTwoFactorService := service_mocks.NewMockTwoFactorService(ctrl)
+	h := &Handler{userService: mockUserService, twoFactorService: mockTwoFactorService, secretKey: "secret"}
+
+	user := &models.User{ID: 7, Login: "test", TOTPEnabled: true}
+	mockUserService.EXPECT().Authenticate(gomock.Any(), "test", "password").Return(nil)
+	mockUserService.EXPECT().GetUserByLogin(gomock.Any(), "test").Return(user, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/login", bytes.NewBufferString(`{"login":"test","password":"password"}`))
+	w := httptest.NewRecorder()
+	h.Login(w, req)
+	resp := w.Result()
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusAccepted)
+	}
+	if resp.Header.Get("Authorization") != "" {
+		t.Error("access token must not be issued before second factor")
+	}
+
+	var challenge models.TwoFactorChallenge
+	if err := json.NewDecoder(resp.Body).Decode(&challenge); err != nil {
+		t.Fatalf("failed to decode challenge: %v", err)
+	}
+	if !challenge.TwoFactorRequired || challenge.ChallengeToken == "" {
+		t.Fatalf("unexpected challenge: %+v", challenge)
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success",
+			body: `{"challenge_token":"` + challenge.ChallengeToken + `","code":"123456"}`,
+			mockSetup: func() {
+				mockTwoFactorService.EXPECT().Verify(gomock.Any(), int64(7), "123456").Return(nil)
+				mockUserService.EXPECT().GetUserByID(gomock.Any(), int64(7)).Return(user, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "invalid code",
+			body: `{"challenge_token":"` + challenge.ChallengeToken + `","code":"000000"}`,
+			mockSetup: func() {
+				mockTwoFactorService.EXPECT().Verify(gomock.Any(), int64(7), "000000").Return(apperrors.ErrInvalidTOTPCode)
+			},
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "invalid challenge",
+			body:           `{"challenge_token":"not-a-token","code":"123456"}`,
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing code",
+			body:           `{"challenge_token":"` + challenge.ChallengeToken + `"}`,
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodPost, "/api/user/login/2fa", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+			h.LoginTwoFactor(w, req)
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			err := resp.Body.Close()
+			if err != nil {
+				return
+			}
+		})
+	}
+}
+
+func TestHandler_ChallengeTokenRejectedAsAccessToken(t *testing.T) {
+	h := &Handler{secretKey: "secret"}
+
+	w := httptest.NewRecorder()
+	h.writeTwoFactorChallenge(w, &models.User{ID: 1})
+
+	var challenge models.TwoFactorChallenge
+	if err := json.NewDecoder(w.Body).Decode(&challenge); err != nil {
+		t.Fatalf("failed to decode challenge: %v", err)
+	}
+
+	protected := middleware.JWTMiddleware("secret", nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/balance", nil)
+	req.Header.Set("Authorization", "Bearer "+challenge.ChallengeToken)
+	rec := httptest.NewRecorder()
+	protected.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestHandler_EnableTwoFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockTwoFactorService := service_mocks.NewMockTwoFactorService(ctrl)
+	h := &Handler{twoFactorService: mockTwoFactorService}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success",
+			body: `{"code":"123456"}`,
+			mockSetup: func() {
+				mockTwoFactorService.EXPECT().Enable(gomock.Any(), int64(1), "123456").Return([]string{"aaaa-bbbb"}, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "not configured",
+			body: `{"code":"123456"}`,
+			mockSetup: func() {
+				mockTwoFactorService.EXPECT().Enable(gomock.Any(), int64(1), "123456").Return(nil, apperrors.ErrTOTPNotConfigured)
+			},
+			wantStatusCode: http.StatusConflict,
+		},
+		{
+			name: "service error",
+			body: `{"code":"123456"}`,
+			mockSetup: func() {
+				mockTwoFactorService.EXPECT().Enable(gomock.Any(), int64(1), "123456").Return(nil, errors.New("fail"))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:           "missing code",
+			body:           `{}`,
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodPost, "/api/user/2fa/enable", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+			w := httptest.NewRecorder()
+			h.EnableTwoFactor(w, req)
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			err := resp.Body.Close()
+			if err != nil {
+				return
+			}
+		})
+	}
+}
+
+func TestHandler_WithdrawRequiresTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockBalanceService := service_mocks.NewMockBalanceService(ctrl)
+	mockTwoFactorService := service_mocks.NewMockTwoFactorService(ctrl)
+	h := &Handler{balanceService: mockBalanceService, twoFactorService: mockTwoFactorService}
+
+	mockTwoFactorService.EXPECT().VerifyWithdrawal(gomock.Any(), int64(1), "").Return(apperrors.ErrTOTPRequired)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/user/balance/withdraw", bytes.NewBufferString(`{"order":"12345678903","sum":100}`))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+	w := httptest.NewRecorder()
+	h.Withdraw(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	h.createWebhook(w, r, userID)
+}
+
+func (h *Handler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	h.listWebhooks(w, r, userID)
+}
+
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	webhookID, ok := webhookIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	err := h.webhookService.Delete(r.Context(), userID, webhookID)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, apperrors.ErrWebhookNotFound):
+		http.Error(w, "webhook not found", http.StatusNotFound)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to delete webhook", zap.Error(err))
+	}
+}
+
+func (h *Handler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	webhookID, ok := webhookIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.webhookService.Deliveries(r.Context(), userID, webhookID)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to list webhook deliveries", zap.Error(err))
+		return
+	}
+	if len(deliveries) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+func (h *Handler) AdminCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.adminService.GetUser(r.Context(), userID); err != nil {
+		writeAdminError(w, r, err)
+		return
+	}
+
+	h.createWebhook(w, r, userID)
+}
+
+func (h *Handler) AdminGetWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := userIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.adminService.GetUser(r.Context(), userID); err != nil {
+		writeAdminError(w, r, err)
+		return
+	}
+
+	h.listWebhooks(w, r, userID)
+}
+
+func (h *Handler) createWebhook(w http.ResponseWriter, r *http.Request, userID int64) {
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+
+	webhook, err := h.webhookService.Create(r.Context(), userID, req)
+	switch {
+	case err == nil:
+	case errors.Is(err, apperrors.ErrInvalidWebhookURL), errors.Is(err, apperrors.ErrInvalidWebhookEvent):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to create webhook", zap.Error(err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, webhook)
+}
+
+func (h *Handler) listWebhooks(w http.ResponseWriter, r *http.Request, userID int64) {
+	webhooks, err := h.webhookService.List(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		logger.FromContext(r.Context()).Error("failed to list webhooks", zap.Error(err))
+		return
+	}
+	if len(webhooks) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, webhooks)
+}
+
+func webhookIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || webhookID <= 0 {
+		http.Error(w, "invalid webhook id", http.StatusBadRequest)
+		return 0, false
+	}
+	return webhookID, true
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/middleware"
+	"github.com/a2sh3r/gophermart/internal/mocks/service_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_CreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockWebhookService := service_mocks.NewMockWebhookService(ctrl)
+	h := &Handler{webhookService: mockWebhookService}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success",
+			body: `{"url":"https://crm.example.com/hooks","event_types":["order.processed"]}`,
+			mockSetup: func() {
+				mockWebhookService.EXPECT().Create(gomock.Any(), int64(1), models.CreateWebhookRequest{
+					URL:        "https://crm.example.com/hooks",
+					EventTypes: []string{"order.processed"},
+				}).Return(&models.CreateWebhookResponse{Secret: "whsec_x"}, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name: "invalid url",
+			body: `{"url":"ftp://crm.example.com"}`,
+			mockSetup: func() {
+				mockWebhookService.EXPECT().Create(gomock.Any(), int64(1), gomock.Any()).Return(nil, apperrors.ErrInvalidWebhookURL)
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "invalid event",
+			body: `{"url":"https://crm.example.com","event_types":["nope"]}`,
+			mockSetup: func() {
+				mockWebhookService.EXPECT().Create(gomock.Any(), int64(1), gomock.Any()).Return(nil, apperrors.ErrInvalidWebhookEvent)
+			},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid json",
+			body:           `{"url":`,
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "service error",
+			body: `{"url":"https://crm.example.com"}`,
+			mockSetup: func() {
+				mockWebhookService.EXPECT().Create(gomock.Any(), int64(1), gomock.Any()).Return(nil, errors.New("fail"))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodPost, "/api/user/webhooks", bytes.NewBufferString(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, int64(1)))
+			w := httptest.NewRecorder()
+			h.CreateWebhook(w, req)
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			err := resp.Body.Close()
+			if err != nil {
+				return
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockWebhookService := service_mocks.NewMockWebhookService(ctrl)
+	h := &Handler{webhookService: mockWebhookService}
+
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success",
+			id:   "5",
+			mockSetup: func() {
+				mockWebhookService.EXPECT().Delete(gomock.Any(), int64(1), int64(5)).Return(nil)
+			},
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name: "not found",
+			id:   "6",
+			mockSetup: func() {
+				mockWebhookService.EXPECT().Delete(gomock.Any(), int64(1), int64(6)).Return(apperrors.ErrWebhookNotFound)
+			},
+			wantStatusCode: http.StatusNotFound,
+		},
+		{
+			name:           "invalid id",
+			id:             "abc",
+			mockSetup:      func() {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodDelete, "/api/user/webhooks/"+tt.id, nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.id)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, middleware.UserIDKey, int64(1))
+			w := httptest.NewRecorder()
+			h.DeleteWebhook(w, req.WithContext(ctx))
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			err := resp.Body.Close()
+			if err != nil {
+				return
+			}
+		})
+	}
+}
+
+func TestHandler_GetWebhookDeliveries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockWebhookService := service_mocks.NewMockWebhookService(ctrl)
+	h := &Handler{webhookService: mockWebhookService}
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				mockWebhookService.EXPECT().Deliveries(gomock.Any(), int64(1), int64(3)).
+					Return([]models.WebhookDelivery{{ID: 1, Status: models.DeliveryDead}}, nil)
+			},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name: "no deliveries",
+			mockSetup: func() {
+				mockWebhookService.EXPECT().Deliveries(gomock.Any(), int64(1), int64(3)).Return(nil, nil)
+			},
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			name: "service error",
+			mockSetup: func() {
+				mockWebhookService.EXPECT().Deliveries(gomock.Any(), int64(1), int64(3)).Return(nil, errors.New("fail"))
+			},
+			wantStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodGet, "/api/user/webhooks/3/deliveries", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "3")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, middleware.UserIDKey, int64(1))
+			w := httptest.NewRecorder()
+			h.GetWebhookDeliveries(w, req.WithContext(ctx))
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			err := resp.Body.Close()
+			if err != nil {
+				return
+			}
+		})
+	}
+}
+
+func TestHandler_AdminCreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockWebhookService := service_mocks.NewMockWebhookService(ctrl)
+	mockAdminService := service_mocks.NewMockAdminService(ctrl)
+	h := &Handler{webhookService: mockWebhookService, adminService: mockAdminService}
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		wantStatusCode int
+	}{
+		{
+			name: "success",
+			mockSetup: func() {
+				mockAdminService.EXPECT().GetUser(gomock.Any(), int64(9)).Return(&models.UserInfo{ID: 9}, nil)
+				mockWebhookService.EXPECT().Create(gomock.Any(), int64(9), gomock.Any()).Return(&models.CreateWebhookResponse{}, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+		},
+		{
+			name: "user not found",
+			mockSetup: func() {
+				mockAdminService.EXPECT().GetUser(gomock.Any(), int64(9)).Return(nil, apperrors.ErrUserNotFound)
+			},
+			wantStatusCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/users/9/webhooks", bytes.NewBufferString(`{"url":"https://crm.example.com"}`))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "9")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			w := httptest.NewRecorder()
+			h.AdminCreateWebhook(w, req.WithContext(ctx))
+			resp := w.Result()
+			if resp.StatusCode != tt.wantStatusCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.wantStatusCode)
+			}
+			err := resp.Body.Close()
+			if err != nil {
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_Run(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     map[string]Check
+		draining   bool
+		wantStatus string
+		wantFailed []string
+	}{
+		{
+			name:       "без проверок",
+			wantStatus: StatusOK,
+		},
+		{
+			name: "все проверки успешны",
+			checks: map[string]Check{
+				"database": func(context.Context) error { return nil },
+				"accrual":  func(context.Context) error { return nil },
+			},
+			wantStatus: StatusOK,
+		},
+		{
+			name: "одна проверка падает",
+			checks: map[string]Check{
+				"database": func(context.Context) error { return errors.New("connection refused") },
+				"accrual":  func(context.Context) error { return nil },
+			},
+			wantStatus: StatusFail,
+			wantFailed: []string{"database"},
+		},
+		{
+			name: "проверка превышает таймаут",
+			checks: map[string]Check{
+				"accrual": func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			},
+			wantStatus: StatusFail,
+			wantFailed: []string{"accrual"},
+		},
+		{
+			name: "инстанс останавливается",
+			checks: map[string]Check{
+				"database": func(context.Context) error { return nil },
+			},
+			draining:   true,
+			wantStatus: StatusFail,
+			wantFailed: []string{"shutdown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(20 * time.Millisecond)
+			for name, check := range tt.checks {
+				c.Add(name, check)
+			}
+			if tt.draining {
+				c.SetDraining()
+			}
+
+			report := c.Run(context.Background())
+
+			assert.Equal(t, tt.wantStatus, report.Status)
+			var failed []string
+			for name, result := range report.Checks {
+				if result.Status == StatusFail {
+					assert.NotEmpty(t, result.Error)
+					failed = append(failed, name)
+				}
+			}
+			assert.ElementsMatch(t, tt.wantFailed, failed)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package logger
+
+import (
+	"context"
+	"go.uber.org/zap"
+)
+
+type ctxKey struct{}
+
+// FromContext returns the request-scoped logger stored in ctx, falling back
+// to the global Log outside of a request.
+func FromContext(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*zap.Logger); ok {
+		return l
+	}
+	return Log
+}
+
+// With returns a context whose logger carries the given fields in addition to
+// those already attached to ctx.
+func With(ctx context.Context, fields ...zap.Field) context.Context {
+	return context.WithValue(ctx, ctxKey{}, FromContext(ctx).With(fields...))
+}
@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	Log = zap.New(core)
+	defer func() { Log = zap.NewNop() }()
+
+	ctx := context.Background()
+	FromContext(ctx).Info("global")
+
+	ctx = With(ctx, zap.String("request_id", "abc"))
+	ctx = With(ctx, zap.Int64("user_id", 7))
+	FromContext(ctx).Info("scoped")
+
+	entries := logs.AllUntimed()
+	if assert.Len(t, entries, 2) {
+		assert.Empty(t, entries[0].Context)
+		assert.Equal(t, map[string]interface{}{"request_id": "abc", "user_id": int64(7)}, entries[1].ContextMap())
+	}
+}
@@ -1,27 +1,80 @@
 package logger
 
 import (
+	"fmt"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
 )
 
 var Log = zap.NewNop()
 
+// level is shared by every logger built by Init, so SetLevel takes effect
+// immediately, including for request-scoped loggers derived from Log.
+var level = zap.NewAtomicLevelAt(zap.InfoLevel)
+
+type Config struct {
+	Level       string
+	Format      string
+	Sampling    bool
+	OutputPaths []string
+}
+
 func Initialize(level string) error {
-	lvl, err := zap.ParseAtomicLevel(level)
+	return Init(Config{Level: level, Format: FormatConsole})
+}
+
+func Init(cfg Config) error {
+	lvl, err := zapcore.ParseLevel(cfg.Level)
 	if err != nil {
 		return err
 	}
 
-	cfg := zap.NewDevelopmentConfig()
+	var zc zap.Config
+	switch cfg.Format {
+	case "", FormatConsole:
+		zc = zap.NewDevelopmentConfig()
+	case FormatJSON:
+		zc = zap.NewProductionConfig()
+	default:
+		return fmt.Errorf("unknown log format %q", cfg.Format)
+	}
 
-	cfg.Level = lvl
+	zc.Level = level
+	zc.Sampling = nil
+	if cfg.Sampling {
+		zc.Sampling = &zap.SamplingConfig{Initial: 100, Thereafter: 100}
+	}
+	if len(cfg.OutputPaths) > 0 {
+		zc.OutputPaths = cfg.OutputPaths
+	}
 
-	zl, err := cfg.Build()
+	zl, err := zc.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newRedactingCore(core)
+	}))
 	if err != nil {
 		return err
 	}
 
+	level.SetLevel(lvl)
 	Log = zl
 
 	return nil
 }
+
+func Level() string {
+	return level.Level().String()
+}
+
+func SetLevel(l string) error {
+	lvl, err := zapcore.ParseLevel(l)
+	if err != nil {
+		return err
+	}
+	level.SetLevel(lvl)
+	return nil
+}
@@ -2,6 +2,8 @@ package logger
 
 import (
 	"testing"
+
+	"go.uber.org/zap"
 )
 
 func TestInitialize(t *testing.T) {
@@ -24,3 +26,48 @@ func TestInitialize(t *testing.T) {
 		})
 	}
 }
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       Config
+		wantError bool
+	}{
+		{"json with sampling", Config{Level: "warn", Format: FormatJSON, Sampling: true, OutputPaths: []string{"stdout"}}, false},
+		{"console", Config{Level: "info", Format: FormatConsole}, false},
+		{"unknown format", Config{Level: "info", Format: "xml"}, true},
+		{"invalid level", Config{Level: "loud", Format: FormatJSON}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Init(tt.cfg)
+			if (err != nil) != tt.wantError {
+				t.Errorf("Init(%+v) error = %v, wantError %v", tt.cfg, err, tt.wantError)
+			}
+			if err == nil && Level() != tt.cfg.Level {
+				t.Errorf("Level() = %q, want %q", Level(), tt.cfg.Level)
+			}
+		})
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	if err := Init(Config{Level: "info", Format: FormatJSON}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+	if !Log.Core().Enabled(zap.DebugLevel) {
+		t.Error("debug level not enabled after SetLevel")
+	}
+
+	if err := SetLevel("verbose"); err == nil {
+		t.Error("SetLevel accepted an invalid level")
+	}
+	if Level() != "debug" {
+		t.Errorf("Level() = %q after failed SetLevel, want debug", Level())
+	}
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are matched as substrings of lower-cased field names; names
+// ending in "_id" are identifiers and are left alone.
+var sensitiveKeys = []string{"password", "secret", "token", "dsn", "authorization", "api_key", "apikey"}
+
+var (
+	urlUserinfo  = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://[^:/@\s]*):[^@\s]*@`)
+	keyValueCred = regexp.MustCompile(`(?i)\b(password|secret|token)\s*=\s*('(?:[^'\\]|\\.)*'|\S+)`)
+	bearerToken  = regexp.MustCompile(`(?i)\bbearer\s+\S+`)
+)
+
+// Redact masks credentials embedded in s: passwords in URLs such as database
+// DSNs, key=value passwords and bearer tokens.
+func Redact(s string) string {
+	s = urlUserinfo.ReplaceAllString(s, "${1}:"+redacted+"@")
+	s = keyValueCred.ReplaceAllString(s, "${1}="+redacted)
+	s = bearerToken.ReplaceAllString(s, "Bearer "+redacted)
+	return s
+}
+
+// redactingCore scrubs fields before they reach the encoder. Fields with a
+// sensitive name are dropped to a placeholder; other strings and errors are
+// passed through Redact.
+type redactingCore struct {
+	zapcore.Core
+}
+
+func newRedactingCore(core zapcore.Core) zapcore.Core {
+	return &redactingCore{Core: core}
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = Redact(entry.Message)
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		out[i] = redactField(f)
+	}
+	return out
+}
+
+func redactField(f zapcore.Field) zapcore.Field {
+	if isSensitiveKey(f.Key) {
+		return zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: redacted}
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		f.String = Redact(f.String)
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			return zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: Redact(err.Error())}
+		}
+	case zapcore.StringerType:
+		if s, ok := f.Interface.(interface{ String() string }); ok {
+			return zapcore.Field{Key: f.Key, Type: zapcore.StringType, String: Redact(s.String())}
+		}
+	}
+	return f
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasSuffix(key, "_id") {
+		return false
+	}
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+
+	ScopesKey contextKey = "scopes"
+)
+
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error)
+}
+
+// AuthMiddleware accepts either a personal API key in the X-API-Key header
+// or a JWT bearer token. Requests authenticated by API key carry the key's
+// scopes in the context so that RequireScope can restrict them.
+func AuthMiddleware(secretKey string, versions TokenVersionProvider, apiKeys APIKeyAuthenticator) func(http.Handler) http.Handler {
+	jwtMiddleware := JWTMiddleware(secretKey, versions)
+
+	return func(next http.Handler) http.Handler {
+		jwtNext := jwtMiddleware(next)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rawKey := r.Header.Get(APIKeyHeader)
+			if rawKey == "" || apiKeys == nil {
+				jwtNext.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := apiKeys.Authenticate(r.Context(), rawKey)
+			if err != nil {
+				http.Error(w, "invalid API key", http.StatusUnauthorized)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), UserIDKey, key.UserID)
+			ctx = context.WithValue(ctx, ScopesKey, key.Scopes)
+			ctx = logger.With(ctx, zap.Int64("user_id", key.UserID), zap.Int64("api_key_id", key.ID))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, ok := GetScopes(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			for _, s := range scopes {
+				if s == scope {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			http.Error(w, "insufficient scope", http.StatusForbidden)
+		})
+	}
+}
+
+func GetScopes(ctx context.Context) ([]string, bool) {
+	scopes, ok := ctx.Value(ScopesKey).([]string)
+	return scopes, ok
+}
@@ -25,6 +25,18 @@ func (w *gzipResponseWriter) Write(b []byte) (int, error) {
 	return w.Writer.Write(b)
 }
 
+// Flush pushes buffered compressed data to the client so that streaming
+// responses such as server-sent events are not held back by the compressor.
+func (w *gzipResponseWriter) Flush() {
+	if gz, ok := w.Writer.(*gzip.Writer); ok {
+		if err := gz.Flush(); err != nil {
+			logger.Log.Error("Failed to flush gzip body", zap.Error(err))
+			return
+		}
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
 func NewGzipMiddleware() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -38,7 +50,7 @@ func NewGzipMiddleware() func(next http.Handler) http.Handler {
 
 				defer func() {
 					if err := gz.Close(); err != nil {
-						logger.Log.Error("Failed to close gzip body", zap.Error(err))
+						logger.FromContext(r.Context()).Error("Failed to close gzip body", zap.Error(err))
 					}
 				}()
 
@@ -57,7 +69,7 @@ func NewGzipMiddleware() func(next http.Handler) http.Handler {
 						return
 					}
 					if err := gz.Close(); err != nil {
-						logger.Log.Error("Failed to close gzip body", zap.Error(err))
+						logger.FromContext(r.Context()).Error("Failed to close gzip body", zap.Error(err))
 					}
 				}()
 			}
@@ -36,6 +36,10 @@ func (rw *hashResponseWriter) WriteHeader(statusCode int) {
 	}
 }
 
+func (rw *hashResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func NewHashMiddleware(secretKey string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -47,14 +51,14 @@ func NewHashMiddleware(secretKey string) func(next http.Handler) http.Handler {
 
 			body, err := io.ReadAll(r.Body)
 			if err != nil {
-				logger.Log.Error("Failed to read request body", zap.Error(err))
+				logger.FromContext(r.Context()).Error("Failed to read request body", zap.Error(err))
 				http.Error(w, "Failed to read request body", http.StatusBadRequest)
 				return
 			}
 
 			err = r.Body.Close()
 			if err != nil {
-				logger.Log.Error("Failed to close request body", zap.Error(err))
+				logger.FromContext(r.Context()).Error("Failed to close request body", zap.Error(err))
 				http.Error(w, "Failed to close request body", http.StatusBadRequest)
 				return
 			}
@@ -62,7 +66,7 @@ func NewHashMiddleware(secretKey string) func(next http.Handler) http.Handler {
 			gotHash := r.Header.Get(HashHeader)
 			if gotHash != "" {
 				if err := hash.VerifyHash(string(body), secretKey, gotHash); err != nil {
-					logger.Log.Error("Hash verification failed", zap.Error(err))
+					logger.FromContext(r.Context()).Error("Hash verification failed", zap.Error(err))
 					http.Error(w, "Hash verification failed", http.StatusBadRequest)
 					return
 				}
@@ -7,14 +7,20 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/a2sh3r/gophermart/internal/logger"
 	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
 )
 
 type contextKey string
 
 const UserIDKey contextKey = "user_id"
 
-func JWTMiddleware(secretKey string) func(http.Handler) http.Handler {
+type TokenVersionProvider interface {
+	GetTokenVersion(ctx context.Context, userID int64) (int64, error)
+}
+
+func JWTMiddleware(secretKey string, versions TokenVersionProvider) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -69,13 +75,30 @@ func JWTMiddleware(secretKey string) func(http.Handler) http.Handler {
 				return
 			}
 
+			if versions != nil {
+				currentVersion, err := versions.GetTokenVersion(r.Context(), userID)
+				if err != nil || tokenVersion(claims) != currentVersion {
+					http.Error(w, "session revoked", http.StatusUnauthorized)
+					return
+				}
+			}
+
 			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			ctx = logger.With(ctx, zap.Int64("user_id", userID))
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+func tokenVersion(claims jwt.MapClaims) int64 {
+	v, ok := claims["token_version"].(float64)
+	if !ok {
+		return 0
+	}
+	return int64(v)
+}
+
 func GetUserID(ctx context.Context) (int64, bool) {
 	id, ok := ctx.Value(UserIDKey).(int64)
 	return id, ok
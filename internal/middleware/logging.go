@@ -24,6 +24,10 @@ func (r *loggingResponseWriter) WriteHeader(statusCode int) {
 	r.responseStatus = statusCode
 }
 
+func (r *loggingResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 func NewLoggingMiddleware() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -38,7 +42,7 @@ func NewLoggingMiddleware() func(next http.Handler) http.Handler {
 
 			duration := time.Since(start)
 
-			logger.Log.Info("HTTP request",
+			logger.FromContext(r.Context()).Info("HTTP request",
 				zap.String("method", r.Method),
 				zap.String("path", r.URL.Path),
 				zap.Int("status", lw.responseStatus),
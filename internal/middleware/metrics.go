@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"github.com/a2sh3r/gophermart/internal/metrics"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// unmatchedRoute labels requests that did not match any route, so that
+// arbitrary paths cannot blow up the label cardinality.
+const unmatchedRoute = "unmatched"
+
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	responseStatus int
+}
+
+func (r *metricsResponseWriter) WriteHeader(statusCode int) {
+	r.ResponseWriter.WriteHeader(statusCode)
+	r.responseStatus = statusCode
+}
+
+func (r *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func NewMetricsMiddleware() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			mw := &metricsResponseWriter{
+				ResponseWriter: w,
+				responseStatus: http.StatusOK,
+			}
+
+			next.ServeHTTP(mw, r)
+
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			status := strconv.Itoa(mw.responseStatus)
+
+			metrics.HTTPRequests.WithLabelValues(r.Method, route, status).Inc()
+			metrics.HTTPRequestDuration.WithLabelValues(r.Method, route, status).Observe(time.Since(start).Seconds())
+		})
+	}
+}
@@ -24,6 +24,19 @@ func NewUserRateLimiter(r rate.Limit, b int) *UserLimiter {
 	}
 }
 
+// SetLimit changes the rate and burst for new and already tracked clients.
+func (u *UserLimiter) SetLimit(r rate.Limit, b int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.r = r
+	u.b = b
+	for _, limiter := range u.limiters {
+		limiter.SetLimit(r)
+		limiter.SetBurst(b)
+	}
+}
+
 func (u *UserLimiter) getLimiter(key string) *rate.Limiter {
 	u.mu.Lock()
 	defer u.mu.Unlock()
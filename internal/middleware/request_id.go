@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	RequestIDKey contextKey = "request_id"
+
+	maxRequestIDLength = 128
+)
+
+// NewRequestIDMiddleware keeps a well-formed X-Request-ID from the caller or
+// generates one, echoes it in the response and attaches it, together with the
+// trace ID when tracing is on, to the request-scoped logger.
+func NewRequestIDMiddleware() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			fields := []zap.Field{zap.String("request_id", requestID)}
+			if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+				fields = append(fields, zap.String("trace_id", sc.TraceID().String()))
+			}
+
+			ctx := context.WithValue(r.Context(), RequestIDKey, requestID)
+			ctx = logger.With(ctx, fields...)
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func GetRequestID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(RequestIDKey).(string)
+	return id, ok
+}
+
+// validRequestID accepts printable ASCII only, so that a client-supplied ID
+// cannot inject anything into log lines or response headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
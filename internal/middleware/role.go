@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/a2sh3r/gophermart/internal/models"
+)
+
+const RoleKey contextKey = "role"
+
+type RoleProvider interface {
+	GetUserRole(ctx context.Context, userID int64) (string, error)
+}
+
+// RequireRole must be mounted after an authentication middleware. It loads
+// the caller's role on every request so that role changes apply immediately.
+func RequireRole(roles RoleProvider, required string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserID(r.Context())
+			if !ok {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			role, ok := GetRole(r.Context())
+			if !ok {
+				var err error
+				role, err = roles.GetUserRole(r.Context(), userID)
+				if err != nil {
+					http.Error(w, "unauthorized", http.StatusUnauthorized)
+					return
+				}
+			}
+
+			if !models.RoleAtLeast(role, required) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), RoleKey, role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func GetRole(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(RoleKey).(string)
+	return role, ok
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"github.com/a2sh3r/gophermart/internal/tracing"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+)
+
+type tracingResponseWriter struct {
+	http.ResponseWriter
+	responseStatus int
+}
+
+func (r *tracingResponseWriter) WriteHeader(statusCode int) {
+	r.ResponseWriter.WriteHeader(statusCode)
+	r.responseStatus = statusCode
+}
+
+func (r *tracingResponseWriter) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// NewTracingMiddleware starts a server span for every request, continuing the
+// caller's trace when a traceparent header is present. The span is named
+// after the matched route pattern once routing is done.
+func NewTracingMiddleware() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracing.Tracer().Start(ctx, r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.path", r.URL.Path),
+				),
+			)
+			defer span.End()
+
+			tw := &tracingResponseWriter{
+				ResponseWriter: w,
+				responseStatus: http.StatusOK,
+			}
+
+			next.ServeHTTP(tw, r.WithContext(ctx))
+
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(
+				attribute.String("http.route", route),
+				attribute.Int("http.response.status_code", tw.responseStatus),
+			)
+			if tw.responseStatus >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(tw.responseStatus))
+			}
+		})
+	}
+}
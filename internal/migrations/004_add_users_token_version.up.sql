@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS token_version BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMPTZ;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS user_totp (
+                                         user_id BIGINT PRIMARY KEY REFERENCES users(id),
+                                         secret TEXT NOT NULL,
+                                         enabled BOOLEAN NOT NULL DEFAULT false,
+                                         last_used_step BIGINT NOT NULL DEFAULT 0,
+                                         created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                         enabled_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS totp_recovery_codes (
+                                                   id SERIAL PRIMARY KEY,
+                                                   user_id BIGINT NOT NULL REFERENCES users(id),
+                                                   code_hash TEXT NOT NULL,
+                                                   used_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_totp_recovery_codes_user_id ON totp_recovery_codes(user_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+                                        id SERIAL PRIMARY KEY,
+                                        user_id BIGINT NOT NULL REFERENCES users(id),
+                                        name TEXT NOT NULL,
+                                        prefix TEXT NOT NULL,
+                                        key_hash TEXT UNIQUE NOT NULL,
+                                        scopes TEXT[] NOT NULL DEFAULT '{}',
+                                        created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                        last_used_at TIMESTAMPTZ,
+                                        revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS balance_adjustments_immutable ON balance_adjustments;
+DROP FUNCTION IF EXISTS forbid_balance_adjustments_change();
+DROP TABLE IF EXISTS balance_adjustments;
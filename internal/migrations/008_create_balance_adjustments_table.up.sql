@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS balance_adjustments (
+                                                   id SERIAL PRIMARY KEY,
+                                                   user_id BIGINT NOT NULL REFERENCES users(id),
+                                                   actor_id BIGINT NOT NULL REFERENCES users(id),
+                                                   amount NUMERIC(12,2) NOT NULL CHECK (amount <> 0),
+                                                   reason TEXT NOT NULL CHECK (reason <> ''),
+                                                   ticket_ref TEXT NOT NULL CHECK (ticket_ref <> ''),
+                                                   created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_adjustments_user_id ON balance_adjustments(user_id);
+
+CREATE OR REPLACE FUNCTION forbid_balance_adjustments_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'balance_adjustments is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER balance_adjustments_immutable
+    BEFORE UPDATE OR DELETE ON balance_adjustments
+    FOR EACH ROW EXECUTE FUNCTION forbid_balance_adjustments_change();
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_orders_user_uploaded_at
+    ON orders (user_id, uploaded_at DESC, number DESC);
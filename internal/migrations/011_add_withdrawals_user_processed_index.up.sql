@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_withdrawals_user_processed_at
+    ON withdrawals (user_id, processed_at DESC, order_number DESC);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    order_number TEXT NOT NULL REFERENCES orders(number) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    accrual DOUBLE PRECISION,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order
+    ON order_status_history (order_number, changed_at);
+
+INSERT INTO order_status_history (order_number, status, accrual, changed_at)
+SELECT number, status, accrual, uploaded_at
+FROM orders;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS order_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    type TEXT NOT NULL,
+    order_number TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT '',
+    accrual DOUBLE PRECISION,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_events_user_id
+    ON order_events (user_id, id);
+
+CREATE INDEX IF NOT EXISTS idx_order_events_created_at
+    ON order_events (created_at);
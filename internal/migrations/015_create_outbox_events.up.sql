@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    user_id BIGINT NOT NULL,
+    aggregate_id TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'processed', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error TEXT NOT NULL DEFAULT '',
+    processed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_due
+    ON outbox_events (next_attempt_at, id)
+    WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_processed_at
+    ON outbox_events (processed_at)
+    WHERE status <> 'pending';
+
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS source_event_id BIGINT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_source_event
+    ON webhook_deliveries (webhook_id, source_event_id);
@@ -35,6 +35,35 @@ func (m *MockBalanceRepository) EXPECT() *MockBalanceRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AdjustBalance mocks base method.
+func (m *MockBalanceRepository) AdjustBalance(ctx context.Context, adjustment *models.BalanceAdjustment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustBalance", ctx, adjustment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustBalance indicates an expected call of AdjustBalance.
+func (mr *MockBalanceRepositoryMockRecorder) AdjustBalance(ctx, adjustment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustBalance", reflect.TypeOf((*MockBalanceRepository)(nil).AdjustBalance), ctx, adjustment)
+}
+
+// GetAdjustments mocks base method.
+func (m *MockBalanceRepository) GetAdjustments(ctx context.Context, userID int64) ([]models.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAdjustments", ctx, userID)
+	ret0, _ := ret[0].([]models.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAdjustments indicates an expected call of GetAdjustments.
+func (mr *MockBalanceRepositoryMockRecorder) GetAdjustments(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAdjustments", reflect.TypeOf((*MockBalanceRepository)(nil).GetAdjustments), ctx, userID)
+}
+
 // GetBalance mocks base method.
 func (m *MockBalanceRepository) GetBalance(ctx context.Context, userID int64) (models.Balance, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +79,36 @@ func (mr *MockBalanceRepositoryMockRecorder) GetBalance(ctx, userID interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockBalanceRepository)(nil).GetBalance), ctx, userID)
 }
 
+// GetBalanceHistory mocks base method.
+func (m *MockBalanceRepository) GetBalanceHistory(ctx context.Context, userID int64) ([]models.BalanceHistoryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceHistory", ctx, userID)
+	ret0, _ := ret[0].([]models.BalanceHistoryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceHistory indicates an expected call of GetBalanceHistory.
+func (mr *MockBalanceRepositoryMockRecorder) GetBalanceHistory(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceHistory", reflect.TypeOf((*MockBalanceRepository)(nil).GetBalanceHistory), ctx, userID)
+}
+
+// GetWithdrawalTotals mocks base method.
+func (m *MockBalanceRepository) GetWithdrawalTotals(ctx context.Context, userID int64, filter models.WithdrawalFilter) (models.WithdrawalTotals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawalTotals", ctx, userID, filter)
+	ret0, _ := ret[0].(models.WithdrawalTotals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawalTotals indicates an expected call of GetWithdrawalTotals.
+func (mr *MockBalanceRepositoryMockRecorder) GetWithdrawalTotals(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalTotals", reflect.TypeOf((*MockBalanceRepository)(nil).GetWithdrawalTotals), ctx, userID, filter)
+}
+
 // GetWithdrawals mocks base method.
 func (m *MockBalanceRepository) GetWithdrawals(ctx context.Context, userID int64) ([]models.Withdrawal, error) {
 	m.ctrl.T.Helper()
@@ -65,30 +124,55 @@ func (mr *MockBalanceRepositoryMockRecorder) GetWithdrawals(ctx, userID interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawals", reflect.TypeOf((*MockBalanceRepository)(nil).GetWithdrawals), ctx, userID)
 }
 
+// GetWithdrawalsPage mocks base method.
+func (m *MockBalanceRepository) GetWithdrawalsPage(ctx context.Context, userID int64, filter models.WithdrawalFilter, after *models.PageCursor) ([]models.Withdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWithdrawalsPage", ctx, userID, filter, after)
+	ret0, _ := ret[0].([]models.Withdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWithdrawalsPage indicates an expected call of GetWithdrawalsPage.
+func (mr *MockBalanceRepositoryMockRecorder) GetWithdrawalsPage(ctx, userID, filter, after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalsPage", reflect.TypeOf((*MockBalanceRepository)(nil).GetWithdrawalsPage), ctx, userID, filter, after)
+}
+
 // IncreaseUserBalance mocks base method.
-func (m *MockBalanceRepository) IncreaseUserBalance(ctx context.Context, userID int64, accrual float64) error {
+func (m *MockBalanceRepository) IncreaseUserBalance(ctx context.Context, userID int64, accrual float64, events ...models.DomainEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IncreaseUserBalance", ctx, userID, accrual)
+	varargs := []interface{}{ctx, userID, accrual}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "IncreaseUserBalance", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // IncreaseUserBalance indicates an expected call of IncreaseUserBalance.
-func (mr *MockBalanceRepositoryMockRecorder) IncreaseUserBalance(ctx, userID, accrual interface{}) *gomock.Call {
+func (mr *MockBalanceRepositoryMockRecorder) IncreaseUserBalance(ctx, userID, accrual interface{}, events ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseUserBalance", reflect.TypeOf((*MockBalanceRepository)(nil).IncreaseUserBalance), ctx, userID, accrual)
+	varargs := append([]interface{}{ctx, userID, accrual}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncreaseUserBalance", reflect.TypeOf((*MockBalanceRepository)(nil).IncreaseUserBalance), varargs...)
 }
 
 // Withdraw mocks base method.
-func (m *MockBalanceRepository) Withdraw(ctx context.Context, withdrawal models.Withdrawal) error {
+func (m *MockBalanceRepository) Withdraw(ctx context.Context, withdrawal models.Withdrawal, events ...models.DomainEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Withdraw", ctx, withdrawal)
+	varargs := []interface{}{ctx, withdrawal}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Withdraw", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Withdraw indicates an expected call of Withdraw.
-func (mr *MockBalanceRepositoryMockRecorder) Withdraw(ctx, withdrawal interface{}) *gomock.Call {
+func (mr *MockBalanceRepositoryMockRecorder) Withdraw(ctx, withdrawal interface{}, events ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockBalanceRepository)(nil).Withdraw), ctx, withdrawal)
+	varargs := append([]interface{}{ctx, withdrawal}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockBalanceRepository)(nil).Withdraw), varargs...)
 }
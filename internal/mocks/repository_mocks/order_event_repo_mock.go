@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/order_event_repository.go
+
+// Package mocks is a generated GoMock package.
+package repository_mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/a2sh3r/gophermart/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOrderEventRepository is a mock of OrderEventRepository interface.
+type MockOrderEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderEventRepositoryMockRecorder
+}
+
+// MockOrderEventRepositoryMockRecorder is the mock recorder for MockOrderEventRepository.
+type MockOrderEventRepositoryMockRecorder struct {
+	mock *MockOrderEventRepository
+}
+
+// NewMockOrderEventRepository creates a new mock instance.
+func NewMockOrderEventRepository(ctrl *gomock.Controller) *MockOrderEventRepository {
+	mock := &MockOrderEventRepository{ctrl: ctrl}
+	mock.recorder = &MockOrderEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderEventRepository) EXPECT() *MockOrderEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteOrderEventsBefore mocks base method.
+func (m *MockOrderEventRepository) DeleteOrderEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrderEventsBefore", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteOrderEventsBefore indicates an expected call of DeleteOrderEventsBefore.
+func (mr *MockOrderEventRepositoryMockRecorder) DeleteOrderEventsBefore(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrderEventsBefore", reflect.TypeOf((*MockOrderEventRepository)(nil).DeleteOrderEventsBefore), ctx, before)
+}
+
+// GetLatestOrderEventID mocks base method.
+func (m *MockOrderEventRepository) GetLatestOrderEventID(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestOrderEventID", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestOrderEventID indicates an expected call of GetLatestOrderEventID.
+func (mr *MockOrderEventRepositoryMockRecorder) GetLatestOrderEventID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestOrderEventID", reflect.TypeOf((*MockOrderEventRepository)(nil).GetLatestOrderEventID), ctx, userID)
+}
+
+// GetOrderEventsSince mocks base method.
+func (m *MockOrderEventRepository) GetOrderEventsSince(ctx context.Context, userID, afterID int64, limit int) ([]models.OrderEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderEventsSince", ctx, userID, afterID, limit)
+	ret0, _ := ret[0].([]models.OrderEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderEventsSince indicates an expected call of GetOrderEventsSince.
+func (mr *MockOrderEventRepositoryMockRecorder) GetOrderEventsSince(ctx, userID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderEventsSince", reflect.TypeOf((*MockOrderEventRepository)(nil).GetOrderEventsSince), ctx, userID, afterID, limit)
+}
+
+// SaveOrderEvent mocks base method.
+func (m *MockOrderEventRepository) SaveOrderEvent(ctx context.Context, event *models.OrderEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOrderEvent", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOrderEvent indicates an expected call of SaveOrderEvent.
+func (mr *MockOrderEventRepositoryMockRecorder) SaveOrderEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrderEvent", reflect.TypeOf((*MockOrderEventRepository)(nil).SaveOrderEvent), ctx, event)
+}
@@ -35,6 +35,21 @@ func (m *MockOrderRepository) EXPECT() *MockOrderRepositoryMockRecorder {
 	return m.recorder
 }
 
+// GetOrder mocks base method.
+func (m *MockOrderRepository) GetOrder(ctx context.Context, number string) (*models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", ctx, number)
+	ret0, _ := ret[0].(*models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockOrderRepositoryMockRecorder) GetOrder(ctx, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockOrderRepository)(nil).GetOrder), ctx, number)
+}
+
 // GetOrderOwner mocks base method.
 func (m *MockOrderRepository) GetOrderOwner(ctx context.Context, number string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +65,21 @@ func (mr *MockOrderRepositoryMockRecorder) GetOrderOwner(ctx, number interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderOwner", reflect.TypeOf((*MockOrderRepository)(nil).GetOrderOwner), ctx, number)
 }
 
+// GetOrderStatusHistory mocks base method.
+func (m *MockOrderRepository) GetOrderStatusHistory(ctx context.Context, number string) ([]models.OrderStatusChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrderStatusHistory", ctx, number)
+	ret0, _ := ret[0].([]models.OrderStatusChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrderStatusHistory indicates an expected call of GetOrderStatusHistory.
+func (mr *MockOrderRepositoryMockRecorder) GetOrderStatusHistory(ctx, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderStatusHistory", reflect.TypeOf((*MockOrderRepository)(nil).GetOrderStatusHistory), ctx, number)
+}
+
 // GetOrdersByUser mocks base method.
 func (m *MockOrderRepository) GetOrdersByUser(ctx context.Context, userID int64) ([]models.Order, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +95,21 @@ func (mr *MockOrderRepositoryMockRecorder) GetOrdersByUser(ctx, userID interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersByUser", reflect.TypeOf((*MockOrderRepository)(nil).GetOrdersByUser), ctx, userID)
 }
 
+// GetOrdersPage mocks base method.
+func (m *MockOrderRepository) GetOrdersPage(ctx context.Context, userID int64, filter models.OrderFilter, after *models.PageCursor) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersPage", ctx, userID, filter, after)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrdersPage indicates an expected call of GetOrdersPage.
+func (mr *MockOrderRepositoryMockRecorder) GetOrdersPage(ctx, userID, filter, after interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersPage", reflect.TypeOf((*MockOrderRepository)(nil).GetOrdersPage), ctx, userID, filter, after)
+}
+
 // GetUnprocessedOrders mocks base method.
 func (m *MockOrderRepository) GetUnprocessedOrders(ctx context.Context) ([]models.Order, error) {
 	m.ctrl.T.Helper()
@@ -81,29 +126,59 @@ func (mr *MockOrderRepositoryMockRecorder) GetUnprocessedOrders(ctx interface{})
 }
 
 // SaveOrder mocks base method.
-func (m *MockOrderRepository) SaveOrder(ctx context.Context, order *models.Order) error {
+func (m *MockOrderRepository) SaveOrder(ctx context.Context, order *models.Order, events ...models.DomainEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveOrder", ctx, order)
+	varargs := []interface{}{ctx, order}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SaveOrder", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SaveOrder indicates an expected call of SaveOrder.
-func (mr *MockOrderRepositoryMockRecorder) SaveOrder(ctx, order interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryMockRecorder) SaveOrder(ctx, order interface{}, events ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, order}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrder", reflect.TypeOf((*MockOrderRepository)(nil).SaveOrder), varargs...)
+}
+
+// SaveOrders mocks base method.
+func (m *MockOrderRepository) SaveOrders(ctx context.Context, orders []models.Order, events ...models.DomainEvent) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, orders}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SaveOrders", varargs...)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveOrders indicates an expected call of SaveOrders.
+func (mr *MockOrderRepositoryMockRecorder) SaveOrders(ctx, orders interface{}, events ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrder", reflect.TypeOf((*MockOrderRepository)(nil).SaveOrder), ctx, order)
+	varargs := append([]interface{}{ctx, orders}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrders", reflect.TypeOf((*MockOrderRepository)(nil).SaveOrders), varargs...)
 }
 
 // UpdateOrderStatus mocks base method.
-func (m *MockOrderRepository) UpdateOrderStatus(ctx context.Context, order *models.Order) error {
+func (m *MockOrderRepository) UpdateOrderStatus(ctx context.Context, order *models.Order, events ...models.DomainEvent) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateOrderStatus", ctx, order)
+	varargs := []interface{}{ctx, order}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateOrderStatus", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateOrderStatus indicates an expected call of UpdateOrderStatus.
-func (mr *MockOrderRepositoryMockRecorder) UpdateOrderStatus(ctx, order interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryMockRecorder) UpdateOrderStatus(ctx, order interface{}, events ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatus", reflect.TypeOf((*MockOrderRepository)(nil).UpdateOrderStatus), ctx, order)
+	varargs := append([]interface{}{ctx, order}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatus", reflect.TypeOf((*MockOrderRepository)(nil).UpdateOrderStatus), varargs...)
 }
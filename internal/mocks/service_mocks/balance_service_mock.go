@@ -35,6 +35,21 @@ func (m *MockBalanceService) EXPECT() *MockBalanceServiceMockRecorder {
 	return m.recorder
 }
 
+// GetHistory mocks base method.
+func (m *MockBalanceService) GetHistory(ctx context.Context, userID int64) ([]models.BalanceHistoryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, userID)
+	ret0, _ := ret[0].([]models.BalanceHistoryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockBalanceServiceMockRecorder) GetHistory(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockBalanceService)(nil).GetHistory), ctx, userID)
+}
+
 // GetUserBalance mocks base method.
 func (m *MockBalanceService) GetUserBalance(ctx context.Context, userID int64) (models.Balance, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +80,21 @@ func (mr *MockBalanceServiceMockRecorder) GetWithdrawals(ctx, userID interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawals", reflect.TypeOf((*MockBalanceService)(nil).GetWithdrawals), ctx, userID)
 }
 
+// ListWithdrawals mocks base method.
+func (m *MockBalanceService) ListWithdrawals(ctx context.Context, userID int64, filter models.WithdrawalFilter) (*models.WithdrawalPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWithdrawals", ctx, userID, filter)
+	ret0, _ := ret[0].(*models.WithdrawalPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWithdrawals indicates an expected call of ListWithdrawals.
+func (mr *MockBalanceServiceMockRecorder) ListWithdrawals(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithdrawals", reflect.TypeOf((*MockBalanceService)(nil).ListWithdrawals), ctx, userID, filter)
+}
+
 // Withdraw mocks base method.
 func (m *MockBalanceService) Withdraw(ctx context.Context, userID int64, withdrawal models.WithdrawalRequest) error {
 	m.ctrl.T.Helper()
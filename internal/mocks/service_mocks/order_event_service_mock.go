@@ -0,0 +1,108 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/service/order_event_service.go
+
+// Package mocks is a generated GoMock package.
+package service_mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	events "github.com/a2sh3r/gophermart/internal/events"
+	models "github.com/a2sh3r/gophermart/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOrderEventService is a mock of OrderEventService interface.
+type MockOrderEventService struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrderEventServiceMockRecorder
+}
+
+// MockOrderEventServiceMockRecorder is the mock recorder for MockOrderEventService.
+type MockOrderEventServiceMockRecorder struct {
+	mock *MockOrderEventService
+}
+
+// NewMockOrderEventService creates a new mock instance.
+func NewMockOrderEventService(ctrl *gomock.Controller) *MockOrderEventService {
+	mock := &MockOrderEventService{ctrl: ctrl}
+	mock.recorder = &MockOrderEventServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrderEventService) EXPECT() *MockOrderEventServiceMockRecorder {
+	return m.recorder
+}
+
+// LatestID mocks base method.
+func (m *MockOrderEventService) LatestID(ctx context.Context, userID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LatestID", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LatestID indicates an expected call of LatestID.
+func (mr *MockOrderEventServiceMockRecorder) LatestID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LatestID", reflect.TypeOf((*MockOrderEventService)(nil).LatestID), ctx, userID)
+}
+
+// Publish mocks base method.
+func (m *MockOrderEventService) Publish(ctx context.Context, event *models.OrderEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockOrderEventServiceMockRecorder) Publish(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockOrderEventService)(nil).Publish), ctx, event)
+}
+
+// RunCleanup mocks base method.
+func (m *MockOrderEventService) RunCleanup(ctx context.Context, interval, retention time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunCleanup", ctx, interval, retention)
+}
+
+// RunCleanup indicates an expected call of RunCleanup.
+func (mr *MockOrderEventServiceMockRecorder) RunCleanup(ctx, interval, retention interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunCleanup", reflect.TypeOf((*MockOrderEventService)(nil).RunCleanup), ctx, interval, retention)
+}
+
+// Since mocks base method.
+func (m *MockOrderEventService) Since(ctx context.Context, userID, afterID int64) ([]models.OrderEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Since", ctx, userID, afterID)
+	ret0, _ := ret[0].([]models.OrderEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Since indicates an expected call of Since.
+func (mr *MockOrderEventServiceMockRecorder) Since(ctx, userID, afterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Since", reflect.TypeOf((*MockOrderEventService)(nil).Since), ctx, userID, afterID)
+}
+
+// Subscribe mocks base method.
+func (m *MockOrderEventService) Subscribe(userID int64) *events.Subscription {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", userID)
+	ret0, _ := ret[0].(*events.Subscription)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockOrderEventServiceMockRecorder) Subscribe(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockOrderEventService)(nil).Subscribe), userID)
+}
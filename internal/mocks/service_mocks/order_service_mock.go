@@ -35,6 +35,21 @@ func (m *MockOrderService) EXPECT() *MockOrderServiceMockRecorder {
 	return m.recorder
 }
 
+// GetUserOrder mocks base method.
+func (m *MockOrderService) GetUserOrder(ctx context.Context, userID int64, number string) (*models.OrderDetail, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserOrder", ctx, userID, number)
+	ret0, _ := ret[0].(*models.OrderDetail)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserOrder indicates an expected call of GetUserOrder.
+func (mr *MockOrderServiceMockRecorder) GetUserOrder(ctx, userID, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserOrder", reflect.TypeOf((*MockOrderService)(nil).GetUserOrder), ctx, userID, number)
+}
+
 // GetUserOrders mocks base method.
 func (m *MockOrderService) GetUserOrders(ctx context.Context, userID int64) ([]models.Order, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +65,21 @@ func (mr *MockOrderServiceMockRecorder) GetUserOrders(ctx, userID interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserOrders", reflect.TypeOf((*MockOrderService)(nil).GetUserOrders), ctx, userID)
 }
 
+// ListUserOrders mocks base method.
+func (m *MockOrderService) ListUserOrders(ctx context.Context, userID int64, filter models.OrderFilter) (*models.OrderPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserOrders", ctx, userID, filter)
+	ret0, _ := ret[0].(*models.OrderPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserOrders indicates an expected call of ListUserOrders.
+func (mr *MockOrderServiceMockRecorder) ListUserOrders(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserOrders", reflect.TypeOf((*MockOrderService)(nil).ListUserOrders), ctx, userID, filter)
+}
+
 // UploadOrder mocks base method.
 func (m *MockOrderService) UploadOrder(ctx context.Context, number string, userID int64) error {
 	m.ctrl.T.Helper()
@@ -63,3 +93,18 @@ func (mr *MockOrderServiceMockRecorder) UploadOrder(ctx, number, userID interfac
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadOrder", reflect.TypeOf((*MockOrderService)(nil).UploadOrder), ctx, number, userID)
 }
+
+// UploadOrders mocks base method.
+func (m *MockOrderService) UploadOrders(ctx context.Context, numbers []string, userID int64) ([]models.BatchOrderResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadOrders", ctx, numbers, userID)
+	ret0, _ := ret[0].([]models.BatchOrderResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadOrders indicates an expected call of UploadOrders.
+func (mr *MockOrderServiceMockRecorder) UploadOrders(ctx, numbers, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadOrders", reflect.TypeOf((*MockOrderService)(nil).UploadOrders), ctx, numbers, userID)
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type UserExport struct {
+	ExportedAt     time.Time             `json:"exported_at"`
+	Profile        UserInfo              `json:"profile"`
+	Balance        Balance               `json:"balance"`
+	Orders         []Order               `json:"orders"`
+	Withdrawals    []Withdrawal          `json:"withdrawals"`
+	BalanceHistory []BalanceHistoryEntry `json:"balance_history"`
+	APIKeys        []APIKey              `json:"api_keys"`
+}
@@ -18,3 +18,52 @@ type Withdrawal struct {
 	Processed time.Time `json:"processed_at" db:"processed_at"`
 	UserID    int64     `json:"-" db:"user_id"`
 }
+
+const (
+	HistoryEntryAccrual    = "accrual"
+	HistoryEntryWithdrawal = "withdrawal"
+	HistoryEntryAdjustment = "adjustment"
+)
+
+type BalanceAdjustmentRequest struct {
+	Amount    float64 `json:"amount"`
+	Reason    string  `json:"reason"`
+	TicketRef string  `json:"ticket_ref"`
+}
+
+type BalanceAdjustment struct {
+	ID        int64     `json:"id" db:"id"`
+	UserID    int64     `json:"user_id" db:"user_id"`
+	ActorID   int64     `json:"actor_id" db:"actor_id"`
+	Amount    float64   `json:"amount" db:"amount"`
+	Reason    string    `json:"reason" db:"reason"`
+	TicketRef string    `json:"ticket_ref" db:"ticket_ref"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type BalanceHistoryEntry struct {
+	Type        string    `json:"type"`
+	Reference   string    `json:"reference"`
+	Amount      float64   `json:"amount"`
+	Description string    `json:"description,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+type WithdrawalFilter struct {
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+	Cursor    string
+	Ascending bool
+}
+
+type WithdrawalTotals struct {
+	Count int64   `json:"count"`
+	Sum   float64 `json:"sum"`
+}
+
+type WithdrawalPage struct {
+	Withdrawals []Withdrawal     `json:"withdrawals"`
+	NextCursor  string           `json:"next_cursor,omitempty"`
+	Totals      WithdrawalTotals `json:"totals"`
+}
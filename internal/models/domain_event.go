@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	EventOrderUploaded      = "OrderUploaded"
+	EventOrderStatusChanged = "OrderStatusChanged"
+	EventBalanceCredited    = "BalanceCredited"
+	EventWithdrawalMade     = "WithdrawalMade"
+)
+
+// DomainEvent is a fact recorded in the outbox in the same transaction as the
+// change it describes. AggregateID identifies the order or withdrawal the
+// event is about; Payload holds one of the *Payload structs below.
+type DomainEvent struct {
+	ID          int64           `json:"id" db:"id"`
+	Type        string          `json:"type" db:"event_type"`
+	UserID      int64           `json:"user_id" db:"user_id"`
+	AggregateID string          `json:"aggregate_id" db:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload" db:"payload"`
+	OccurredAt  time.Time       `json:"occurred_at" db:"occurred_at"`
+	Attempts    int             `json:"-" db:"attempts"`
+}
+
+type OrderUploadedPayload struct {
+	Number     string    `json:"number"`
+	Status     string    `json:"status"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+type OrderStatusChangedPayload struct {
+	Number         string   `json:"number"`
+	PreviousStatus string   `json:"previous_status"`
+	Status         string   `json:"status"`
+	Accrual        *float64 `json:"accrual,omitempty"`
+}
+
+type BalanceCreditedPayload struct {
+	OrderNumber string  `json:"order"`
+	Amount      float64 `json:"amount"`
+}
+
+type WithdrawalMadePayload struct {
+	OrderNumber string    `json:"order"`
+	Sum         float64   `json:"sum"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
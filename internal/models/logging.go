@@ -0,0 +1,5 @@
+package models
+
+type LogLevel struct {
+	Level string `json:"level"`
+}
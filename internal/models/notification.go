@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	NotificationOrderProcessed  = "order_processed"
+	NotificationOrderInvalid    = "order_invalid"
+	NotificationLargeWithdrawal = "large_withdrawal"
+)
+
+type Notification struct {
+	ID        int64           `json:"id" db:"id"`
+	UserID    int64           `json:"-" db:"user_id"`
+	Type      string          `json:"type" db:"type"`
+	Title     string          `json:"title" db:"title"`
+	Body      string          `json:"body" db:"body"`
+	Data      json.RawMessage `json:"data,omitempty" db:"data"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	ReadAt    *time.Time      `json:"read_at,omitempty" db:"read_at"`
+	// SourceEventID is the outbox event the notification was created from.
+	SourceEventID int64 `json:"-" db:"source_event_id"`
+}
+
+type NotificationFilter struct {
+	UnreadOnly bool
+	Limit      int
+	Cursor     string
+}
+
+type NotificationPage struct {
+	Notifications []Notification
+	UnreadCount   int64
+	NextCursor    string
+}
+
+type UnreadCount struct {
+	Unread int64 `json:"unread"`
+}
+
+// NotificationSettings holds per-user delivery preferences. The inbox is
+// always written; Email is only used when EmailEnabled is set.
+type NotificationSettings struct {
+	UserID       int64  `json:"-" db:"user_id"`
+	Email        string `json:"email" db:"email"`
+	EmailEnabled bool   `json:"email_enabled" db:"email_enabled"`
+}
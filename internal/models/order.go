@@ -9,3 +9,39 @@ type Order struct {
 	UploadedAt time.Time `json:"uploaded_at" db:"uploaded_at"`
 	UserID     int64     `json:"-" db:"user_id"`
 }
+
+type OrderFilter struct {
+	Statuses []string
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	Cursor   string
+}
+
+type OrderPage struct {
+	Orders     []Order `json:"orders"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
+type OrderStatusChange struct {
+	Status    string    `json:"status" db:"status"`
+	Accrual   *float64  `json:"accrual,omitempty" db:"accrual"`
+	ChangedAt time.Time `json:"changed_at" db:"changed_at"`
+}
+
+type OrderDetail struct {
+	Order
+	History []OrderStatusChange `json:"history"`
+}
+
+const (
+	BatchOrderAccepted     = "accepted"
+	BatchOrderAlreadyYours = "already_yours"
+	BatchOrderConflict     = "conflict"
+	BatchOrderInvalid      = "invalid"
+)
+
+type BatchOrderResult struct {
+	Number string `json:"number"`
+	Status string `json:"status"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+const (
+	OrderEventStatusChanged   = "order.status_changed"
+	OrderEventBalanceCredited = "balance.credited"
+)
+
+type OrderEvent struct {
+	ID          int64     `json:"id" db:"id"`
+	UserID      int64     `json:"-" db:"user_id"`
+	Type        string    `json:"type" db:"type"`
+	OrderNumber string    `json:"order" db:"order_number"`
+	Status      string    `json:"status,omitempty" db:"status"`
+	Accrual     *float64  `json:"accrual,omitempty" db:"accrual"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// PageCursor identifies the last row of a page in keyset pagination:
+// rows are ordered by Time descending with Key as a tie-breaker.
+type PageCursor struct {
+	Time time.Time
+	Key  string
+}
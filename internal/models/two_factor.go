@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+type TOTP struct {
+	UserID         int64      `json:"-" db:"user_id"`
+	Secret         string     `json:"-" db:"secret"`
+	Enabled        bool       `json:"enabled" db:"enabled"`
+	LastUsedStep   int64      `json:"-" db:"last_used_step"`
+	EnabledAt      *time.Time `json:"enabled_at,omitempty" db:"enabled_at"`
+	FailedAttempts int        `json:"-" db:"failed_attempts"`
+	LockedUntil    *time.Time `json:"-" db:"locked_until"`
+}
+
+type TOTPSetup struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
@@ -1,7 +1,52 @@
 package models
 
+const (
+	RoleUser    = "user"
+	RoleSupport = "support"
+	RoleAdmin   = "admin"
+)
+
+var roleRank = map[string]int{
+	RoleUser:    1,
+	RoleSupport: 2,
+	RoleAdmin:   3,
+}
+
 type User struct {
-	ID       int64  `json:"-" db:"id"`
-	Login    string `json:"login" db:"login"`
-	Password string `json:"password,omitempty" db:"password_hash"`
+	ID           int64  `json:"-" db:"id"`
+	Login        string `json:"login" db:"login"`
+	Password     string `json:"password,omitempty" db:"password_hash"`
+	TokenVersion int64  `json:"-" db:"token_version"`
+	TOTPEnabled  bool   `json:"-" db:"totp_enabled"`
+	Role         string `json:"-" db:"role"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type UserInfo struct {
+	ID          int64  `json:"id"`
+	Login       string `json:"login"`
+	Role        string `json:"role"`
+	TOTPEnabled bool   `json:"totp_enabled"`
+}
+
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
+
+func IsValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// RoleAtLeast reports whether role grants at least the privileges of required.
+func RoleAtLeast(role, required string) bool {
+	rank, ok := roleRank[role]
+	if !ok {
+		return false
+	}
+	return rank >= roleRank[required]
 }
@@ -0,0 +1,15 @@
+package notify
+
+import (
+	"context"
+	"github.com/a2sh3r/gophermart/internal/models"
+)
+
+// Channel delivers a notification outside the inbox. Channels are best
+// effort: the inbox entry is written first and stays the source of truth.
+type Channel interface {
+	Name() string
+	// Enabled reports whether the user opted in to this channel.
+	Enabled(settings models.NotificationSettings) bool
+	Send(ctx context.Context, settings models.NotificationSettings, notification models.Notification) error
+}
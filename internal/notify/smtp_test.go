@@ -0,0 +1,125 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// startFakeSMTP accepts a single session, speaking just enough SMTP for
+// net/smtp, and reports the received message.
+func startFakeSMTP(t *testing.T) (string, <-chan fakeMessage) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	messages := make(chan fakeMessage, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		tp := textproto.NewConn(conn)
+		var msg fakeMessage
+		_ = tp.PrintfLine("220 fake ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				_ = tp.PrintfLine("250-fake")
+				_ = tp.PrintfLine("250 8BITMIME")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				msg.from = angleAddr(line)
+				_ = tp.PrintfLine("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				msg.to = append(msg.to, angleAddr(line))
+				_ = tp.PrintfLine("250 OK")
+			case cmd == "DATA":
+				_ = tp.PrintfLine("354 go ahead")
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				msg.data = string(data)
+				_ = tp.PrintfLine("250 queued")
+			case cmd == "QUIT":
+				_ = tp.PrintfLine("221 bye")
+				messages <- msg
+				return
+			default:
+				_ = tp.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), messages
+}
+
+func angleAddr(line string) string {
+	_, rest, _ := strings.Cut(line, "<")
+	addr, _, _ := strings.Cut(rest, ">")
+	return addr
+}
+
+func TestSMTPChannel_Send(t *testing.T) {
+	addr, messages := startFakeSMTP(t)
+
+	channel, err := NewSMTPChannel(SMTPConfig{Addr: addr, From: "noreply@gophermart.test", Timeout: 5 * time.Second})
+	require.NoError(t, err)
+
+	settings := models.NotificationSettings{Email: "user@example.com", EmailEnabled: true}
+	require.True(t, channel.Enabled(settings))
+
+	err = channel.Send(context.Background(), settings, models.Notification{
+		Title: "Order 12345678903 processed\r\nBcc: evil@example.com",
+		Body:  "You earned 100.00 points.\n.\nThanks",
+	})
+	require.NoError(t, err)
+
+	select {
+	case msg := <-messages:
+		assert.Equal(t, "noreply@gophermart.test", msg.from)
+		assert.Equal(t, []string{"user@example.com"}, msg.to)
+
+		headers, err := textproto.NewReader(bufio.NewReader(strings.NewReader(msg.data))).ReadMIMEHeader()
+		require.NoError(t, err)
+		assert.Equal(t, "Order 12345678903 processed  Bcc: evil@example.com", headers.Get("Subject"))
+		assert.Empty(t, headers.Get("Bcc"))
+		assert.Contains(t, msg.data, "You earned 100.00 points.\n.\nThanks")
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake SMTP server received no message")
+	}
+}
+
+func TestSMTPChannel_Enabled(t *testing.T) {
+	channel, err := NewSMTPChannel(SMTPConfig{Addr: "localhost:25", From: "noreply@gophermart.test"})
+	require.NoError(t, err)
+
+	assert.False(t, channel.Enabled(models.NotificationSettings{Email: "user@example.com"}))
+	assert.False(t, channel.Enabled(models.NotificationSettings{EmailEnabled: true}))
+
+	_, err = NewSMTPChannel(SMTPConfig{Addr: "localhost", From: "noreply@gophermart.test"})
+	assert.Error(t, err)
+}
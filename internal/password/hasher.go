@@ -0,0 +1,262 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+	ErrMalformedHash    = errors.New("malformed password hash")
+	ErrInvalidParams    = errors.New("invalid password hash parameters")
+)
+
+// Hasher hashes passwords into self-describing strings. Stored hashes come in
+// two formats: argon2id uses the PHC string format
+// ($argon2id$v=19$m=...,t=...,p=...$salt$key), while bcrypt keeps its native
+// modular crypt format ($2a$, $2b$ or $2y$ followed by the cost). The prefix
+// alone tells them apart, so both can live in the same column.
+type Hasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (bool, error)
+	NeedsRehash(encoded string) bool
+}
+
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+func DefaultArgon2Params() Argon2Params {
+	return Argon2Params{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+type HasherConfig struct {
+	Algorithm  string
+	Argon2     Argon2Params
+	BcryptCost int
+}
+
+// NewHasher returns a Hasher that produces hashes with the configured
+// algorithm and verifies hashes produced by any supported algorithm, so
+// that stored hashes can be migrated on the next successful login.
+func NewHasher(cfg HasherConfig) (Hasher, error) {
+	if cfg.Algorithm != AlgorithmArgon2id && cfg.Algorithm != AlgorithmBcrypt {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, cfg.Algorithm)
+	}
+	if err := cfg.Argon2.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ValidateBcryptCost(cfg.BcryptCost); err != nil {
+		return nil, err
+	}
+
+	argon := &Argon2idHasher{Params: cfg.Argon2}
+	bc := &BcryptHasher{Cost: cfg.BcryptCost}
+	if cfg.Algorithm == AlgorithmBcrypt {
+		return &multiHasher{preferred: bc, argon2id: argon, bcrypt: bc}, nil
+	}
+	return &multiHasher{preferred: argon, argon2id: argon, bcrypt: bc}, nil
+}
+
+// Validate rejects parameters argon2 cannot work with: zero iterations or
+// parallelism make it panic, and an empty key would match any password.
+func (p Argon2Params) Validate() error {
+	switch {
+	case p.Memory < 1:
+		return fmt.Errorf("%w: argon2 memory must be at least 1", ErrInvalidParams)
+	case p.Iterations < 1:
+		return fmt.Errorf("%w: argon2 iterations must be at least 1", ErrInvalidParams)
+	case p.Parallelism < 1:
+		return fmt.Errorf("%w: argon2 parallelism must be at least 1", ErrInvalidParams)
+	case p.SaltLength < 1 || p.KeyLength < 1:
+		return fmt.Errorf("%w: argon2 salt and key length must be at least 1", ErrInvalidParams)
+	}
+	return nil
+}
+
+func ValidateBcryptCost(cost int) error {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return fmt.Errorf("%w: bcrypt cost must be between %d and %d", ErrInvalidParams, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return nil
+}
+
+type multiHasher struct {
+	preferred Hasher
+	argon2id  *Argon2idHasher
+	bcrypt    *BcryptHasher
+}
+
+func (h *multiHasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+func (h *multiHasher) Verify(password, encoded string) (bool, error) {
+	switch algorithmOf(encoded) {
+	case AlgorithmArgon2id:
+		return h.argon2id.Verify(password, encoded)
+	case AlgorithmBcrypt:
+		return h.bcrypt.Verify(password, encoded)
+	default:
+		return false, ErrUnknownAlgorithm
+	}
+}
+
+func (h *multiHasher) NeedsRehash(encoded string) bool {
+	return h.preferred.NeedsRehash(encoded)
+}
+
+type Argon2idHasher struct {
+	Params Argon2Params
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.Params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Params.Iterations, h.Params.Memory, h.Params.Parallelism, h.Params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.Params.Memory,
+		h.Params.Iterations,
+		h.Params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Argon2idHasher) Verify(password, encoded string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, salt, _, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+
+	return params.Memory != h.Params.Memory ||
+		params.Iterations != h.Params.Iterations ||
+		params.Parallelism != h.Params.Parallelism ||
+		params.KeyLength != h.Params.KeyLength ||
+		uint32(len(salt)) != h.Params.SaltLength
+}
+
+func decodeArgon2id(encoded string) (Argon2Params, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return Argon2Params{}, nil, nil, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return Argon2Params{}, nil, nil, ErrMalformedHash
+	}
+	if version != argon2.Version {
+		return Argon2Params{}, nil, nil, fmt.Errorf("%w: unsupported argon2 version %d", ErrMalformedHash, version)
+	}
+
+	var params Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Argon2Params{}, nil, nil, ErrMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, ErrMalformedHash
+	}
+	params.SaltLength = uint32(len(salt))
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Argon2Params{}, nil, nil, ErrMalformedHash
+	}
+	params.KeyLength = uint32(len(key))
+
+	if params.Validate() != nil {
+		return Argon2Params{}, nil, nil, ErrMalformedHash
+	}
+
+	return params, salt, key, nil
+}
+
+// BcryptHasher produces native bcrypt hashes rather than PHC strings, which
+// keeps hashes created before argon2id was added readable as they are.
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+func (h *BcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	if algorithmOf(encoded) != AlgorithmBcrypt {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return true
+	}
+	return cost != h.Cost
+}
+
+// algorithmOf detects the algorithm from the hash prefix, see Hasher.
+func algorithmOf(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return AlgorithmArgon2id
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return AlgorithmBcrypt
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,153 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func testArgon2Params() Argon2Params {
+	return Argon2Params{
+		Memory:      1024,
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+func TestArgon2idHasher_HashAndVerify(t *testing.T) {
+	h := &Argon2idHasher{Params: testArgon2Params()}
+
+	encoded, err := h.Hash("secret-password")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("unexpected PHC string: %s", encoded)
+	}
+
+	ok, err := h.Verify("secret-password", encoded)
+	if err != nil || !ok {
+		t.Errorf("Verify() = %v, %v; want true, nil", ok, err)
+	}
+
+	ok, err = h.Verify("wrong-password", encoded)
+	if err != nil || ok {
+		t.Errorf("Verify() = %v, %v; want false, nil", ok, err)
+	}
+
+	if h.NeedsRehash(encoded) {
+		t.Error("hash with current params should not need rehash")
+	}
+
+	stronger := &Argon2idHasher{Params: testArgon2Params()}
+	stronger.Params.Iterations = 2
+	if !stronger.NeedsRehash(encoded) {
+		t.Error("hash with outdated params should need rehash")
+	}
+}
+
+func TestArgon2idHasher_MalformedHash(t *testing.T) {
+	h := &Argon2idHasher{Params: testArgon2Params()}
+
+	tests := []string{
+		"",
+		"$argon2id$v=19$m=1024,t=1,p=1$salt",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$garbage$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5",
+		"$argon2id$v=19$m=1024,t=0,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=0$c2FsdA$a2V5",
+	}
+
+	for _, encoded := range tests {
+		if _, err := h.Verify("password", encoded); !errors.Is(err, ErrMalformedHash) {
+			t.Errorf("Verify(%q) error = %v, want ErrMalformedHash", encoded, err)
+		}
+	}
+}
+
+func TestBcryptHasher_NeedsRehash(t *testing.T) {
+	h := &BcryptHasher{Cost: bcrypt.MinCost}
+
+	encoded, err := h.Hash("secret-password")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	ok, err := h.Verify("secret-password", encoded)
+	if err != nil || !ok {
+		t.Errorf("Verify() = %v, %v; want true, nil", ok, err)
+	}
+
+	if h.NeedsRehash(encoded) {
+		t.Error("hash with current cost should not need rehash")
+	}
+
+	if !(&BcryptHasher{Cost: bcrypt.MinCost + 1}).NeedsRehash(encoded) {
+		t.Error("hash with outdated cost should need rehash")
+	}
+}
+
+func TestNewHasher(t *testing.T) {
+	argonHasher, err := NewHasher(HasherConfig{Algorithm: AlgorithmArgon2id, Argon2: testArgon2Params(), BcryptCost: bcrypt.MinCost})
+	if err != nil {
+		t.Fatalf("NewHasher() error = %v", err)
+	}
+
+	legacy, err := (&BcryptHasher{Cost: bcrypt.MinCost}).Hash("secret-password")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	ok, err := argonHasher.Verify("secret-password", legacy)
+	if err != nil || !ok {
+		t.Errorf("Verify() of bcrypt hash = %v, %v; want true, nil", ok, err)
+	}
+	if !argonHasher.NeedsRehash(legacy) {
+		t.Error("bcrypt hash should need rehash when argon2id is preferred")
+	}
+
+	fresh, err := argonHasher.Hash("secret-password")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if argonHasher.NeedsRehash(fresh) {
+		t.Error("fresh argon2id hash should not need rehash")
+	}
+
+	if _, err := argonHasher.Verify("secret-password", "plaintext"); !errors.Is(err, ErrUnknownAlgorithm) {
+		t.Errorf("Verify() error = %v, want ErrUnknownAlgorithm", err)
+	}
+
+	if _, err := NewHasher(HasherConfig{Algorithm: "md5"}); !errors.Is(err, ErrUnknownAlgorithm) {
+		t.Errorf("NewHasher() error = %v, want ErrUnknownAlgorithm", err)
+	}
+}
+
+func TestNewHasher_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *HasherConfig)
+	}{
+		{name: "zero memory", modify: func(cfg *HasherConfig) { cfg.Argon2.Memory = 0 }},
+		{name: "zero iterations", modify: func(cfg *HasherConfig) { cfg.Argon2.Iterations = 0 }},
+		{name: "zero parallelism", modify: func(cfg *HasherConfig) { cfg.Argon2.Parallelism = 0 }},
+		{name: "zero key length", modify: func(cfg *HasherConfig) { cfg.Argon2.KeyLength = 0 }},
+		{name: "bcrypt cost too low", modify: func(cfg *HasherConfig) { cfg.BcryptCost = bcrypt.MinCost - 1 }},
+		{name: "bcrypt cost too high", modify: func(cfg *HasherConfig) { cfg.BcryptCost = bcrypt.MaxCost + 1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := HasherConfig{Algorithm: AlgorithmArgon2id, Argon2: testArgon2Params(), BcryptCost: bcrypt.MinCost}
+			tt.modify(&cfg)
+			if _, err := NewHasher(cfg); !errors.Is(err, ErrInvalidParams) {
+				t.Errorf("NewHasher() error = %v, want ErrInvalidParams", err)
+			}
+		})
+	}
+}
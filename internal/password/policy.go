@@ -0,0 +1,64 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+)
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+var commonPasswords = loadCommonPasswords(commonPasswordsFile)
+
+type Policy struct {
+	MinLength   int
+	CheckCommon bool
+}
+
+func NewPolicy(minLength int, checkCommon bool) Policy {
+	return Policy{
+		MinLength:   minLength,
+		CheckCommon: checkCommon,
+	}
+}
+
+func (p Policy) Validate(login, password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters long", apperrors.ErrWeakPassword, p.MinLength)
+	}
+
+	if login != "" && strings.EqualFold(password, login) {
+		return fmt.Errorf("%w: must not be equal to login", apperrors.ErrWeakPassword)
+	}
+
+	if p.CheckCommon && IsCommon(password) {
+		return fmt.Errorf("%w: password is too common", apperrors.ErrWeakPassword)
+	}
+
+	return nil
+}
+
+func IsCommon(password string) bool {
+	_, ok := commonPasswords[strings.ToLower(password)]
+	return ok
+}
+
+func loadCommonPasswords(data string) map[string]struct{} {
+	passwords := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+
+	return passwords
+}
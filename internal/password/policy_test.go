@@ -0,0 +1,46 @@
+package password
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+)
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   Policy
+		login    string
+		password string
+		wantErr  bool
+	}{
+		{"valid password", NewPolicy(8, true), "user", "correct-horse-battery", false},
+		{"too short", NewPolicy(8, true), "user", "x7#kq", true},
+		{"equals login", NewPolicy(4, true), "someuser", "SomeUser", true},
+		{"common password", NewPolicy(6, true), "user", "qwerty123", true},
+		{"common password allowed when check disabled", NewPolicy(6, false), "user", "qwerty123", false},
+		{"multibyte length", NewPolicy(4, false), "user", "пароль", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.login, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, apperrors.ErrWeakPassword) {
+				t.Errorf("expected ErrWeakPassword, got %v", err)
+			}
+		})
+	}
+}
+
+func TestIsCommon(t *testing.T) {
+	if !IsCommon("Password") {
+		t.Error("expected Password to be reported as common")
+	}
+	if IsCommon("s8#Lq!vR2@zT") {
+		t.Error("expected random password not to be reported as common")
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyRepo_Lifecycle(t *testing.T) {
+	r := NewAPIKeyRepository(testDB)
+	ctx := context.Background()
+
+	setupUserTestData(t, testDB)
+
+	key := &models.APIKey{
+		UserID:  1,
+		Name:    "partner",
+		Prefix:  "gm_abcdef12",
+		KeyHash: "hash",
+		Scopes:  []string{models.ScopeOrdersRead, models.ScopeOrdersWrite},
+	}
+	require.NoError(t, r.CreateAPIKey(ctx, key))
+	assert.NotZero(t, key.ID)
+
+	found, err := r.GetAPIKeyByHash(ctx, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, key.Scopes, found.Scopes)
+	assert.Nil(t, found.RevokedAt)
+
+	require.NoError(t, r.TouchAPIKey(ctx, key.ID))
+
+	keys, err := r.GetAPIKeysByUser(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.NotNil(t, keys[0].LastUsedAt)
+
+	assert.ErrorIs(t, r.RevokeAPIKey(ctx, 2, key.ID), apperrors.ErrAPIKeyNotFound)
+	require.NoError(t, r.RevokeAPIKey(ctx, 1, key.ID))
+	assert.ErrorIs(t, r.RevokeAPIKey(ctx, 1, key.ID), apperrors.ErrAPIKeyNotFound)
+
+	found, err = r.GetAPIKeyByHash(ctx, "hash")
+	require.NoError(t, err)
+	assert.NotNil(t, found.RevokedAt)
+
+	_, err = r.GetAPIKeyByHash(ctx, "missing")
+	assert.ErrorIs(t, err, apperrors.ErrAPIKeyNotFound)
+}
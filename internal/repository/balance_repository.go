@@ -4,54 +4,115 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
 	"github.com/a2sh3r/gophermart/internal/logger"
 	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/tracing"
 	"go.uber.org/zap"
+	"strings"
 )
 
 type BalanceRepository interface {
 	GetBalance(ctx context.Context, userID int64) (models.Balance, error)
-	Withdraw(ctx context.Context, withdrawal models.Withdrawal) error
+	Withdraw(ctx context.Context, withdrawal models.Withdrawal, events ...models.DomainEvent) error
 	GetWithdrawals(ctx context.Context, userID int64) ([]models.Withdrawal, error)
-	IncreaseUserBalance(ctx context.Context, userID int64, accrual float64) error
+	GetWithdrawalsPage(ctx context.Context, userID int64, filter models.WithdrawalFilter, after *models.PageCursor) ([]models.Withdrawal, error)
+	GetWithdrawalTotals(ctx context.Context, userID int64, filter models.WithdrawalFilter) (models.WithdrawalTotals, error)
+	IncreaseUserBalance(ctx context.Context, userID int64, accrual float64, events ...models.DomainEvent) error
+	AdjustBalance(ctx context.Context, adjustment *models.BalanceAdjustment) error
+	GetAdjustments(ctx context.Context, userID int64) ([]models.BalanceAdjustment, error)
+	GetBalanceHistory(ctx context.Context, userID int64) ([]models.BalanceHistoryEntry, error)
 }
 
 type balanceRepo struct {
-	db *sql.DB
+	db     *sql.DB
+	router *DBRouter
 }
 
 func NewBalanceRepository(db *sql.DB) BalanceRepository {
-	return &balanceRepo{db: db}
+	return NewRoutedBalanceRepository(NewDBRouter(db, nil, 0))
 }
 
-func (r *balanceRepo) GetBalance(ctx context.Context, userID int64) (models.Balance, error) {
+// NewRoutedBalanceRepository returns a repository that writes to the router's
+// primary and sends balance and withdrawal list reads to its replica.
+func NewRoutedBalanceRepository(router *DBRouter) BalanceRepository {
+	return &balanceRepo{db: router.Primary(), router: router}
+}
+
+func (r *balanceRepo) GetBalance(ctx context.Context, userID int64) (_ models.Balance, err error) {
+	ctx, span := tracing.Start(ctx, "BalanceRepository.GetBalance")
+	defer func() { tracing.End(span, err) }()
+
 	var balance models.Balance
 	query := `
 		SELECT current_balance, withdrawn_balance FROM users WHERE id = $1
 	`
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(&balance.Current, &balance.Withdrawn)
+	err = r.router.Read(ctx, userID, func(db *sql.DB) error {
+		return db.QueryRowContext(ctx, query, userID).Scan(&balance.Current, &balance.Withdrawn)
+	})
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return models.Balance{Current: 0, Withdrawn: 0}, nil
 	}
 	if err != nil {
-		logger.Log.Error("failed to get balance", zap.Error(err))
+		logger.FromContext(ctx).Error("failed to get balance", zap.Error(err))
 		return models.Balance{}, err
 	}
 	return balance, nil
 }
 
-func (r *balanceRepo) IncreaseUserBalance(ctx context.Context, userID int64, accrual float64) error {
+func (r *balanceRepo) IncreaseUserBalance(ctx context.Context, userID int64, accrual float64, events ...models.DomainEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "BalanceRepository.IncreaseUserBalance")
+	defer func() { tracing.End(span, err) }()
+	defer func() {
+		if err == nil {
+			r.router.MarkWrite(userID)
+		}
+	}()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			if err != nil {
+				logger.FromContext(ctx).Error("rollback error")
+				return
+			}
+		}
+	}()
+
 	query := `
 		UPDATE users
 		SET current_balance = current_balance + $1
 		WHERE id = $2
 	`
-	_, err := r.db.ExecContext(ctx, query, accrual, userID)
+	_, err = tx.ExecContext(ctx, query, accrual, userID)
+	if err != nil {
+		return err
+	}
+
+	err = saveDomainEvents(ctx, tx, events)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
 	return err
 }
 
-func (r *balanceRepo) Withdraw(ctx context.Context, withdrawal models.Withdrawal) error {
+func (r *balanceRepo) Withdraw(ctx context.Context, withdrawal models.Withdrawal, events ...models.DomainEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "BalanceRepository.Withdraw")
+	defer func() { tracing.End(span, err) }()
+	defer func() {
+		if err == nil {
+			r.router.MarkWrite(withdrawal.UserID)
+		}
+	}()
+
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -60,7 +121,7 @@ func (r *balanceRepo) Withdraw(ctx context.Context, withdrawal models.Withdrawal
 		if err != nil {
 			err := tx.Rollback()
 			if err != nil {
-				logger.Log.Error("rollback error")
+				logger.FromContext(ctx).Error("rollback error")
 				return
 			}
 		}
@@ -75,7 +136,7 @@ func (r *balanceRepo) Withdraw(ctx context.Context, withdrawal models.Withdrawal
 		return err
 	}
 
-	_, err = tx.ExecContext(ctx, `
+	res, err := tx.ExecContext(ctx, `
 		UPDATE users
 		SET current_balance = current_balance - $1,
 		    withdrawn_balance = withdrawn_balance + $1
@@ -85,23 +146,104 @@ func (r *balanceRepo) Withdraw(ctx context.Context, withdrawal models.Withdrawal
 		return err
 	}
 
+	// The funds check before the call may have read a stale replica; the
+	// conditional update is what actually guards the balance.
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		err = apperrors.ErrInsufficientFunds
+		return err
+	}
+
+	err = saveDomainEvents(ctx, tx, events)
+	if err != nil {
+		return err
+	}
+
 	err = tx.Commit()
 	return err
 }
 
-func (r *balanceRepo) GetWithdrawals(ctx context.Context, userID int64) ([]models.Withdrawal, error) {
+func (r *balanceRepo) GetWithdrawals(ctx context.Context, userID int64) (_ []models.Withdrawal, err error) {
+	ctx, span := tracing.Start(ctx, "BalanceRepository.GetWithdrawals")
+	defer func() { tracing.End(span, err) }()
+
 	query := `
 		SELECT order_number, sum, processed_at FROM withdrawals WHERE user_id = $1 ORDER BY processed_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	var withdrawals []models.Withdrawal
+	err = r.router.Read(ctx, userID, func(db *sql.DB) error {
+		withdrawals = nil
+		rows, err := db.QueryContext(ctx, query, userID)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to query withdrawals", zap.Error(err))
+			return err
+		}
+		defer func(rows *sql.Rows) {
+			err := rows.Close()
+			if err != nil {
+				logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
+			}
+		}(rows)
+
+		for rows.Next() {
+			var w models.Withdrawal
+			if err := rows.Scan(&w.Order, &w.Sum, &w.Processed); err != nil {
+				logger.FromContext(ctx).Error("failed to scan withdrawal", zap.Error(err))
+				return err
+			}
+			w.UserID = userID
+			withdrawals = append(withdrawals, w)
+		}
+
+		if err := rows.Err(); err != nil {
+			logger.FromContext(ctx).Error("error iterating over withdrawals", zap.Error(err))
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		logger.Log.Error("failed to query withdrawals", zap.Error(err))
+		return nil, err
+	}
+
+	return withdrawals, nil
+}
+
+func (r *balanceRepo) GetWithdrawalsPage(ctx context.Context, userID int64, filter models.WithdrawalFilter, after *models.PageCursor) (_ []models.Withdrawal, err error) {
+	ctx, span := tracing.Start(ctx, "BalanceRepository.GetWithdrawalsPage")
+	defer func() { tracing.End(span, err) }()
+
+	conditions, args := withdrawalConditions(userID, filter)
+
+	order, cmp := "DESC", "<"
+	if filter.Ascending {
+		order, cmp = "ASC", ">"
+	}
+
+	if after != nil {
+		args = append(args, after.Time, after.Key)
+		conditions = append(conditions, fmt.Sprintf("(processed_at, order_number) %s ($%d, $%d)", cmp, len(args)-1, len(args)))
+	}
+
+	args = append(args, filter.Limit)
+	query := fmt.Sprintf(`
+		SELECT order_number, sum, processed_at FROM withdrawals
+		WHERE %s
+		ORDER BY processed_at %s, order_number %s
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), order, order, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to query withdrawals page", zap.Error(err))
 		return nil, err
 	}
 	defer func(rows *sql.Rows) {
 		err := rows.Close()
 		if err != nil {
-			logger.Log.Error("failed to close rows", zap.Error(err))
+			logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
 		}
 	}(rows)
 
@@ -109,7 +251,7 @@ func (r *balanceRepo) GetWithdrawals(ctx context.Context, userID int64) ([]model
 	for rows.Next() {
 		var w models.Withdrawal
 		if err := rows.Scan(&w.Order, &w.Sum, &w.Processed); err != nil {
-			logger.Log.Error("failed to scan withdrawal", zap.Error(err))
+			logger.FromContext(ctx).Error("failed to scan withdrawal", zap.Error(err))
 			return nil, err
 		}
 		w.UserID = userID
@@ -117,9 +259,180 @@ func (r *balanceRepo) GetWithdrawals(ctx context.Context, userID int64) ([]model
 	}
 
 	if err := rows.Err(); err != nil {
-		logger.Log.Error("error iterating over withdrawals", zap.Error(err))
+		logger.FromContext(ctx).Error("error iterating over withdrawals page", zap.Error(err))
 		return nil, err
 	}
 
 	return withdrawals, nil
 }
+
+func (r *balanceRepo) GetWithdrawalTotals(ctx context.Context, userID int64, filter models.WithdrawalFilter) (_ models.WithdrawalTotals, err error) {
+	ctx, span := tracing.Start(ctx, "BalanceRepository.GetWithdrawalTotals")
+	defer func() { tracing.End(span, err) }()
+
+	conditions, args := withdrawalConditions(userID, filter)
+	query := `SELECT COUNT(*), COALESCE(SUM(sum), 0) FROM withdrawals WHERE ` + strings.Join(conditions, " AND ")
+
+	var totals models.WithdrawalTotals
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(&totals.Count, &totals.Sum)
+	return totals, err
+}
+
+func withdrawalConditions(userID int64, filter models.WithdrawalFilter) ([]string, []interface{}) {
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{userID}
+
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("processed_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("processed_at < $%d", len(args)))
+	}
+
+	return conditions, args
+}
+
+func (r *balanceRepo) AdjustBalance(ctx context.Context, adjustment *models.BalanceAdjustment) (err error) {
+	ctx, span := tracing.Start(ctx, "BalanceRepository.AdjustBalance")
+	defer func() { tracing.End(span, err) }()
+	defer func() {
+		if err == nil {
+			r.router.MarkWrite(adjustment.UserID)
+		}
+	}()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			if err != nil {
+				logger.FromContext(ctx).Error("rollback error")
+				return
+			}
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET current_balance = current_balance + $1
+		WHERE id = $2 AND current_balance + $1 >= 0
+	`, adjustment.Amount, adjustment.UserID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		err = apperrors.ErrInsufficientFunds
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO balance_adjustments (user_id, actor_id, amount, reason, ticket_ref)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, adjustment.UserID, adjustment.ActorID, adjustment.Amount, adjustment.Reason, adjustment.TicketRef).
+		Scan(&adjustment.ID, &adjustment.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	return err
+}
+
+func (r *balanceRepo) GetAdjustments(ctx context.Context, userID int64) (_ []models.BalanceAdjustment, err error) {
+	ctx, span := tracing.Start(ctx, "BalanceRepository.GetAdjustments")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT id, user_id, actor_id, amount, reason, ticket_ref, created_at
+		FROM balance_adjustments
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to query balance adjustments", zap.Error(err))
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var adjustments []models.BalanceAdjustment
+	for rows.Next() {
+		var a models.BalanceAdjustment
+		if err := rows.Scan(&a.ID, &a.UserID, &a.ActorID, &a.Amount, &a.Reason, &a.TicketRef, &a.CreatedAt); err != nil {
+			logger.FromContext(ctx).Error("failed to scan balance adjustment", zap.Error(err))
+			return nil, err
+		}
+		adjustments = append(adjustments, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("error iterating over balance adjustments", zap.Error(err))
+		return nil, err
+	}
+
+	return adjustments, nil
+}
+
+func (r *balanceRepo) GetBalanceHistory(ctx context.Context, userID int64) (_ []models.BalanceHistoryEntry, err error) {
+	ctx, span := tracing.Start(ctx, "BalanceRepository.GetBalanceHistory")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT 'accrual' AS type, number AS reference, accrual::float8 AS amount, '' AS description, uploaded_at AS occurred_at
+		FROM orders
+		WHERE user_id = $1 AND status = 'PROCESSED' AND accrual > 0
+		UNION ALL
+		SELECT 'withdrawal', order_number, -sum::float8, '', processed_at
+		FROM withdrawals
+		WHERE user_id = $1
+		UNION ALL
+		SELECT 'adjustment', ticket_ref, amount::float8, reason, created_at
+		FROM balance_adjustments
+		WHERE user_id = $1
+		ORDER BY occurred_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to query balance history", zap.Error(err))
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var history []models.BalanceHistoryEntry
+	for rows.Next() {
+		var e models.BalanceHistoryEntry
+		if err := rows.Scan(&e.Type, &e.Reference, &e.Amount, &e.Description, &e.OccurredAt); err != nil {
+			logger.FromContext(ctx).Error("failed to scan balance history entry", zap.Error(err))
+			return nil, err
+		}
+		history = append(history, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("error iterating over balance history", zap.Error(err))
+		return nil, err
+	}
+
+	return history, nil
+}
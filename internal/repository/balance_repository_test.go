@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/a2sh3r/gophermart/internal/apperrors"
 	"github.com/a2sh3r/gophermart/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -250,7 +251,7 @@ func TestBalanceRepo_Withdraw(t *testing.T) {
 				Processed: time.Now(),
 				UserID:    1,
 			},
-			wantErr: false,
+			wantErr: true,
 			setupFunc: func() {
 				setupTestData(t, testDB)
 			},
@@ -263,7 +264,7 @@ func TestBalanceRepo_Withdraw(t *testing.T) {
 				Processed: time.Now(),
 				UserID:    2,
 			},
-			wantErr: false,
+			wantErr: true,
 			setupFunc: func() {
 				setupTestData(t, testDB)
 			},
@@ -282,7 +283,19 @@ func TestBalanceRepo_Withdraw(t *testing.T) {
 
 			err = r.Withdraw(ctx, tt.withdrawal)
 			if tt.wantErr {
-				assert.Error(t, err)
+				assert.ErrorIs(t, err, apperrors.ErrInsufficientFunds)
+
+				var count int
+				err = testDB.QueryRowContext(ctx, `SELECT COUNT(*) FROM withdrawals WHERE order_number = $1`, tt.withdrawal.Order).Scan(&count)
+				assert.NoError(t, err)
+				assert.Equal(t, 0, count, "withdrawal must be rolled back")
+
+				var newCurrent float64
+				err = testDB.QueryRowContext(ctx, `SELECT current_balance FROM users WHERE id = $1`, tt.withdrawal.UserID).Scan(&newCurrent)
+				if err != nil && err != sql.ErrNoRows {
+					assert.NoError(t, err)
+				}
+				assert.Equal(t, initialCurrent, newCurrent)
 				return
 			}
 			assert.NoError(t, err)
@@ -298,16 +311,8 @@ func TestBalanceRepo_Withdraw(t *testing.T) {
 			assert.NoError(t, err)
 			assert.Equal(t, 1, count)
 
-			if tt.name == "successful withdrawal" {
-				expectedCurrent := initialCurrent - tt.withdrawal.Sum
-				expectedWithdrawn := initialWithdrawn + tt.withdrawal.Sum
-
-				assert.Equal(t, expectedCurrent, newCurrent)
-				assert.Equal(t, expectedWithdrawn, newWithdrawn)
-			} else {
-				assert.Equal(t, initialCurrent, newCurrent)
-				assert.Equal(t, initialWithdrawn, newWithdrawn)
-			}
+			assert.Equal(t, initialCurrent-tt.withdrawal.Sum, newCurrent)
+			assert.Equal(t, initialWithdrawn+tt.withdrawal.Sum, newWithdrawn)
 		})
 	}
 }
@@ -385,3 +390,94 @@ func TestBalanceRepo_GetWithdrawals(t *testing.T) {
 		})
 	}
 }
+
+func TestBalanceRepo_AdjustBalance(t *testing.T) {
+	r := NewBalanceRepository(testDB)
+	ctx := context.Background()
+
+	setupTestData(t, testDB)
+
+	credit := &models.BalanceAdjustment{UserID: 2, ActorID: 3, Amount: 25, Reason: "compensation", TicketRef: "SUP-1"}
+	require.NoError(t, r.AdjustBalance(ctx, credit))
+	assert.NotZero(t, credit.ID)
+
+	balance, err := r.GetBalance(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, balance.Current)
+
+	debit := &models.BalanceAdjustment{UserID: 2, ActorID: 3, Amount: -100, Reason: "fraud", TicketRef: "SUP-2"}
+	assert.ErrorIs(t, r.AdjustBalance(ctx, debit), apperrors.ErrInsufficientFunds)
+
+	balance, err = r.GetBalance(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, balance.Current)
+
+	adjustments, err := r.GetAdjustments(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, adjustments, 1)
+	assert.Equal(t, "SUP-1", adjustments[0].TicketRef)
+
+	_, err = testDB.Exec(`UPDATE balance_adjustments SET amount = 1000 WHERE id = $1`, credit.ID)
+	assert.Error(t, err)
+
+	_, err = testDB.Exec(`DELETE FROM balance_adjustments WHERE id = $1`, credit.ID)
+	assert.Error(t, err)
+}
+
+func TestBalanceRepo_GetBalanceHistory(t *testing.T) {
+	r := NewBalanceRepository(testDB)
+	ctx := context.Background()
+
+	setupTestData(t, testDB)
+
+	require.NoError(t, r.AdjustBalance(ctx, &models.BalanceAdjustment{UserID: 1, ActorID: 3, Amount: 10, Reason: "bonus", TicketRef: "SUP-3"}))
+
+	history, err := r.GetBalanceHistory(ctx, 1)
+	require.NoError(t, err)
+
+	counts := map[string]int{}
+	for _, e := range history {
+		counts[e.Type]++
+	}
+	assert.Equal(t, 2, counts[models.HistoryEntryAccrual])
+	assert.Equal(t, 2, counts[models.HistoryEntryWithdrawal])
+	assert.Equal(t, 1, counts[models.HistoryEntryAdjustment])
+
+	for i := 1; i < len(history); i++ {
+		assert.False(t, history[i].OccurredAt.After(history[i-1].OccurredAt))
+	}
+}
+
+func TestBalanceRepo_GetWithdrawalsPage(t *testing.T) {
+	r := NewBalanceRepository(testDB)
+	ctx := context.Background()
+
+	setupTestData(t, testDB)
+
+	desc, err := r.GetWithdrawalsPage(ctx, 3, models.WithdrawalFilter{Limit: 1}, nil)
+	require.NoError(t, err)
+	require.Len(t, desc, 1)
+	assert.Equal(t, "withdraw4", desc[0].Order)
+
+	next, err := r.GetWithdrawalsPage(ctx, 3, models.WithdrawalFilter{Limit: 1}, &models.PageCursor{Time: desc[0].Processed, Key: desc[0].Order})
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	assert.Equal(t, "withdraw3", next[0].Order)
+
+	asc, err := r.GetWithdrawalsPage(ctx, 3, models.WithdrawalFilter{Limit: 10, Ascending: true}, nil)
+	require.NoError(t, err)
+	require.Len(t, asc, 2)
+	assert.Equal(t, "withdraw3", asc[0].Order)
+
+	from := time.Now().Add(-36 * time.Hour)
+	totals, err := r.GetWithdrawalTotals(ctx, 1, models.WithdrawalFilter{From: &from})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), totals.Count)
+	assert.Equal(t, float64(50), totals.Sum)
+
+	to := time.Now().Add(-12 * time.Hour)
+	totals, err = r.GetWithdrawalTotals(ctx, 1, models.WithdrawalFilter{From: &from, To: &to})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), totals.Count)
+	assert.Equal(t, float64(20), totals.Sum)
+}
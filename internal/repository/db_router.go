@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/metrics"
+	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// replicaRetryAfter is how long reads stay on the primary after the replica
+// failed a query.
+const replicaRetryAfter = 30 * time.Second
+
+// DBRouter picks the database for read-only queries. Reads go to the replica
+// unless there is none, the user wrote something within the read-your-writes
+// window, or the replica recently failed. Writes always use the primary.
+//
+// Recent writes are tracked in memory, so the guarantee holds for requests
+// served by the same instance.
+type DBRouter struct {
+	primary *sql.DB
+	replica *sql.DB
+	window  time.Duration
+
+	mu     sync.Mutex
+	writes map[int64]time.Time
+
+	replicaDownUntil atomic.Int64
+	now              func() time.Time
+}
+
+// NewDBRouter returns a router over primary and an optional replica; with a
+// nil replica every query goes to the primary.
+func NewDBRouter(primary, replica *sql.DB, window time.Duration) *DBRouter {
+	return &DBRouter{
+		primary: primary,
+		replica: replica,
+		window:  window,
+		writes:  make(map[int64]time.Time),
+		now:     time.Now,
+	}
+}
+
+func (r *DBRouter) Primary() *sql.DB {
+	return r.primary
+}
+
+// MarkWrite starts the read-your-writes window of userID.
+func (r *DBRouter) MarkWrite(userID int64) {
+	if r.replica == nil || r.window <= 0 {
+		return
+	}
+
+	now := r.now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writes[userID] = now.Add(r.window)
+	if len(r.writes) > 1024 {
+		for id, until := range r.writes {
+			if !now.Before(until) {
+				delete(r.writes, id)
+			}
+		}
+	}
+}
+
+// Read runs fn against the database that should serve a read-only query of
+// userID. If the replica fails, it is skipped for a while and fn is retried on
+// the primary, so fn must not keep state between calls.
+func (r *DBRouter) Read(ctx context.Context, userID int64, fn func(db *sql.DB) error) error {
+	db := r.readDB(userID)
+	err := fn(db)
+	if err == nil || db == r.primary || errors.Is(err, sql.ErrNoRows) || ctx.Err() != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Warn("replica query failed, falling back to primary", zap.Error(err))
+	r.replicaDownUntil.Store(r.now().Add(replicaRetryAfter).UnixNano())
+	metrics.DBReplicaFallbacks.Inc()
+	return fn(r.primary)
+}
+
+func (r *DBRouter) readDB(userID int64) *sql.DB {
+	if r.replica == nil {
+		return r.primary
+	}
+
+	now := r.now()
+	if now.UnixNano() < r.replicaDownUntil.Load() {
+		return r.primary
+	}
+
+	r.mu.Lock()
+	until, ok := r.writes[userID]
+	r.mu.Unlock()
+	if ok && now.Before(until) {
+		return r.primary
+	}
+	return r.replica
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(t *testing.T, window time.Duration) (*DBRouter, *sql.DB, *sql.DB) {
+	t.Helper()
+	primary, err := sql.Open("postgres", "postgres://primary/gophermart?sslmode=disable")
+	require.NoError(t, err)
+	replica, err := sql.Open("postgres", "postgres://replica/gophermart?sslmode=disable")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = primary.Close()
+		_ = replica.Close()
+	})
+	return NewDBRouter(primary, replica, window), primary, replica
+}
+
+func readTarget(t *testing.T, r *DBRouter, userID int64) *sql.DB {
+	t.Helper()
+	var used *sql.DB
+	err := r.Read(context.Background(), userID, func(db *sql.DB) error {
+		used = db
+		return nil
+	})
+	require.NoError(t, err)
+	return used
+}
+
+func TestDBRouter_Read(t *testing.T) {
+	now := time.Now()
+	r, primary, replica := newTestRouter(t, 5*time.Second)
+	r.now = func() time.Time { return now }
+
+	assert.Same(t, replica, readTarget(t, r, 1), "reads go to the replica")
+
+	r.MarkWrite(1)
+	assert.Same(t, primary, readTarget(t, r, 1), "own writes are read from the primary")
+	assert.Same(t, replica, readTarget(t, r, 2), "other users still read from the replica")
+
+	now = now.Add(5 * time.Second)
+	assert.Same(t, replica, readTarget(t, r, 1), "window is over")
+}
+
+func TestDBRouter_ReadFallback(t *testing.T) {
+	now := time.Now()
+	r, primary, replica := newTestRouter(t, 0)
+	r.now = func() time.Time { return now }
+
+	var calls []*sql.DB
+	err := r.Read(context.Background(), 1, func(db *sql.DB) error {
+		calls = append(calls, db)
+		if db == replica {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []*sql.DB{replica, primary}, calls)
+	assert.Same(t, primary, readTarget(t, r, 1), "failed replica is skipped")
+
+	now = now.Add(replicaRetryAfter)
+	assert.Same(t, replica, readTarget(t, r, 1), "replica is retried later")
+
+	calls = nil
+	err = r.Read(context.Background(), 1, func(db *sql.DB) error {
+		calls = append(calls, db)
+		return sql.ErrNoRows
+	})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Equal(t, []*sql.DB{replica}, calls, "no rows is not a replica failure")
+}
+
+func TestDBRouter_NoReplica(t *testing.T) {
+	primary, err := sql.Open("postgres", "postgres://primary/gophermart?sslmode=disable")
+	require.NoError(t, err)
+	defer func() { _ = primary.Close() }()
+
+	r := NewDBRouter(primary, nil, 5*time.Second)
+	r.MarkWrite(1)
+	assert.Same(t, primary, readTarget(t, r, 1))
+	assert.Same(t, primary, readTarget(t, r, 2))
+}
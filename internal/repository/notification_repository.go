@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"go.uber.org/zap"
+	"strings"
+)
+
+type NotificationRepository interface {
+	CreateNotification(ctx context.Context, notification *models.Notification) (bool, error)
+	GetNotificationsPage(ctx context.Context, userID int64, filter models.NotificationFilter, after *models.PageCursor) ([]models.Notification, error)
+	CountUnread(ctx context.Context, userID int64) (int64, error)
+	MarkRead(ctx context.Context, userID, notificationID int64) error
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+	GetSettings(ctx context.Context, userID int64) (models.NotificationSettings, error)
+	SaveSettings(ctx context.Context, settings models.NotificationSettings) error
+}
+
+type notificationRepo struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepo{db: db}
+}
+
+// CreateNotification inserts the notification unless one of the same type was
+// already created for the source event or the user has been deleted, and
+// reports whether it did.
+func (r *notificationRepo) CreateNotification(ctx context.Context, n *models.Notification) (bool, error) {
+	query := `
+		INSERT INTO notifications (user_id, type, title, body, data, source_event_id)
+		SELECT $1, $2, $3, $4, $5, $6
+		WHERE EXISTS (SELECT 1 FROM users WHERE id = $1 AND deleted_at IS NULL)
+		ON CONFLICT (user_id, type, source_event_id) DO NOTHING
+		RETURNING id, created_at
+	`
+	var data []byte
+	if len(n.Data) > 0 {
+		data = n.Data
+	}
+
+	err := r.db.QueryRowContext(ctx, query, n.UserID, n.Type, n.Title, n.Body, data, n.SourceEventID).
+		Scan(&n.ID, &n.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *notificationRepo) GetNotificationsPage(ctx context.Context, userID int64, filter models.NotificationFilter, after *models.PageCursor) ([]models.Notification, error) {
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{userID}
+
+	if filter.UnreadOnly {
+		conditions = append(conditions, "read_at IS NULL")
+	}
+	if after != nil {
+		args = append(args, after.Time, after.Key)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d::bigint)", len(args)-1, len(args)))
+	}
+
+	args = append(args, filter.Limit)
+	query := fmt.Sprintf(`
+		SELECT id, type, title, body, data, created_at, read_at FROM notifications
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to query notifications", zap.Error(err))
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var notifications []models.Notification
+	for rows.Next() {
+		var n models.Notification
+		var data []byte
+		if err := rows.Scan(&n.ID, &n.Type, &n.Title, &n.Body, &data, &n.CreatedAt, &n.ReadAt); err != nil {
+			logger.FromContext(ctx).Error("failed to scan notification", zap.Error(err))
+			return nil, err
+		}
+		n.UserID = userID
+		n.Data = data
+		notifications = append(notifications, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("error iterating over notifications", zap.Error(err))
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+func (r *notificationRepo) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// MarkRead is idempotent: marking an already read notification succeeds and
+// keeps the original read time.
+func (r *notificationRepo) MarkRead(ctx context.Context, userID, notificationID int64) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, now())
+		WHERE id = $1 AND user_id = $2
+	`, notificationID, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperrors.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepo) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE notifications SET read_at = now() WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *notificationRepo) GetSettings(ctx context.Context, userID int64) (models.NotificationSettings, error) {
+	settings := models.NotificationSettings{UserID: userID}
+	err := r.db.QueryRowContext(ctx,
+		`SELECT email, email_enabled FROM notification_settings WHERE user_id = $1`, userID).
+		Scan(&settings.Email, &settings.EmailEnabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return settings, nil
+	}
+	return settings, err
+}
+
+func (r *notificationRepo) SaveSettings(ctx context.Context, settings models.NotificationSettings) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO notification_settings (user_id, email, email_enabled)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET email = EXCLUDED.email, email_enabled = EXCLUDED.email_enabled
+	`, settings.UserID, settings.Email, settings.EmailEnabled)
+	return err
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationRepo_Inbox(t *testing.T) {
+	r := NewNotificationRepository(testDB)
+	ctx := context.Background()
+
+	setupUserTestData(t, testDB)
+
+	for i := int64(1); i <= 3; i++ {
+		created, err := r.CreateNotification(ctx, &models.Notification{
+			UserID: 1, Type: models.NotificationOrderProcessed, Title: "t", Body: "b", SourceEventID: i,
+		})
+		require.NoError(t, err)
+		assert.True(t, created)
+	}
+
+	created, err := r.CreateNotification(ctx, &models.Notification{
+		UserID: 1, Type: models.NotificationOrderProcessed, Title: "t", Body: "b", SourceEventID: 1,
+	})
+	require.NoError(t, err)
+	assert.False(t, created, "the same source event must not create a second entry")
+
+	require.NoError(t, NewUserRepository(testDB).DeleteUser(ctx, 2, "deleted-abc"))
+	created, err = r.CreateNotification(ctx, &models.Notification{
+		UserID: 2, Type: models.NotificationOrderProcessed, Title: "t", Body: "b", SourceEventID: 1,
+	})
+	require.NoError(t, err)
+	assert.False(t, created, "deleted users get no notifications")
+
+	count, err := r.CountUnread(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+
+	page, err := r.GetNotificationsPage(ctx, 1, models.NotificationFilter{Limit: 2}, nil)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Greater(t, page[0].ID, page[1].ID)
+
+	require.NoError(t, r.MarkRead(ctx, 1, page[0].ID))
+	require.NoError(t, r.MarkRead(ctx, 1, page[0].ID))
+	assert.ErrorIs(t, r.MarkRead(ctx, 2, page[0].ID), apperrors.ErrNotificationNotFound)
+
+	unread, err := r.GetNotificationsPage(ctx, 1, models.NotificationFilter{UnreadOnly: true, Limit: 10}, nil)
+	require.NoError(t, err)
+	assert.Len(t, unread, 2)
+
+	marked, err := r.MarkAllRead(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), marked)
+
+	count, err = r.CountUnread(ctx, 1)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestNotificationRepo_Settings(t *testing.T) {
+	r := NewNotificationRepository(testDB)
+	ctx := context.Background()
+
+	setupUserTestData(t, testDB)
+
+	settings, err := r.GetSettings(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.NotificationSettings{UserID: 1}, settings)
+
+	require.NoError(t, r.SaveSettings(ctx, models.NotificationSettings{UserID: 1, Email: "a@example.com", EmailEnabled: true}))
+	require.NoError(t, r.SaveSettings(ctx, models.NotificationSettings{UserID: 1, Email: "b@example.com"}))
+
+	settings, err = r.GetSettings(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "b@example.com", settings.Email)
+	assert.False(t, settings.EmailEnabled)
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// OrderEventsChannel is the Postgres NOTIFY channel used to announce new
+// order events. The payload is the id of the user the event belongs to.
+const OrderEventsChannel = "order_events"
+
+type OrderEventRepository interface {
+	SaveOrderEvent(ctx context.Context, event *models.OrderEvent) error
+	GetOrderEventsSince(ctx context.Context, userID, afterID int64, limit int) ([]models.OrderEvent, error)
+	GetLatestOrderEventID(ctx context.Context, userID int64) (int64, error)
+	DeleteOrderEventsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type orderEventRepo struct {
+	db *sql.DB
+}
+
+func NewOrderEventRepository(db *sql.DB) OrderEventRepository {
+	return &orderEventRepo{db: db}
+}
+
+func (r *orderEventRepo) SaveOrderEvent(ctx context.Context, event *models.OrderEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			if err != nil {
+				logger.FromContext(ctx).Error("rollback error")
+				return
+			}
+		}
+	}()
+
+	query := `
+		INSERT INTO order_events (user_id, type, order_number, status, accrual)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(ctx, query, event.UserID, event.Type, event.OrderNumber, event.Status, event.Accrual).
+		Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `SELECT pg_notify($1, $2)`, OrderEventsChannel, strconv.FormatInt(event.UserID, 10))
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	return err
+}
+
+func (r *orderEventRepo) GetOrderEventsSince(ctx context.Context, userID, afterID int64, limit int) ([]models.OrderEvent, error) {
+	query := `
+		SELECT id, user_id, type, order_number, status, accrual, created_at
+		FROM order_events
+		WHERE user_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, afterID, limit)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to query order events", zap.Error(err))
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var events []models.OrderEvent
+	for rows.Next() {
+		var e models.OrderEvent
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Type, &e.OrderNumber, &e.Status, &e.Accrual, &e.CreatedAt); err != nil {
+			logger.FromContext(ctx).Error("failed to scan order event", zap.Error(err))
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("error iterating over order events", zap.Error(err))
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *orderEventRepo) GetLatestOrderEventID(ctx context.Context, userID int64) (int64, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM order_events WHERE user_id = $1`, userID).Scan(&id)
+	return id, err
+}
+
+func (r *orderEventRepo) DeleteOrderEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM order_events WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderEventRepo_Lifecycle(t *testing.T) {
+	r := NewOrderEventRepository(testDB)
+	ctx := context.Background()
+
+	setupUserTestData(t, testDB)
+	_, err := testDB.Exec(`TRUNCATE order_events RESTART IDENTITY`)
+	require.NoError(t, err)
+
+	latest, err := r.GetLatestOrderEventID(ctx, 1)
+	require.NoError(t, err)
+	assert.Zero(t, latest)
+
+	for _, status := range []string{"PROCESSING", "PROCESSED"} {
+		event := &models.OrderEvent{UserID: 1, Type: models.OrderEventStatusChanged, OrderNumber: "12345678903", Status: status}
+		require.NoError(t, r.SaveOrderEvent(ctx, event))
+		assert.NotZero(t, event.ID)
+	}
+	require.NoError(t, r.SaveOrderEvent(ctx, &models.OrderEvent{UserID: 2, Type: models.OrderEventStatusChanged, OrderNumber: "79927398713"}))
+
+	events, err := r.GetOrderEventsSince(ctx, 1, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "PROCESSING", events[0].Status)
+
+	events, err = r.GetOrderEventsSince(ctx, 1, events[0].ID, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "PROCESSED", events[0].Status)
+
+	latest, err = r.GetLatestOrderEventID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, events[0].ID, latest)
+
+	deleted, err := r.DeleteOrderEventsBefore(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+}
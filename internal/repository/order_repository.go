@@ -4,81 +4,293 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
 	"github.com/a2sh3r/gophermart/internal/logger"
 	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/tracing"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type OrderRepository interface {
-	SaveOrder(ctx context.Context, order *models.Order) error
+	SaveOrder(ctx context.Context, order *models.Order, events ...models.DomainEvent) error
+	SaveOrders(ctx context.Context, orders []models.Order, events ...models.DomainEvent) (map[string]int64, error)
 	GetOrdersByUser(ctx context.Context, userID int64) ([]models.Order, error)
+	GetOrdersPage(ctx context.Context, userID int64, filter models.OrderFilter, after *models.PageCursor) ([]models.Order, error)
 	GetOrderOwner(ctx context.Context, number string) (int64, error)
+	GetOrder(ctx context.Context, number string) (*models.Order, error)
+	GetOrderStatusHistory(ctx context.Context, number string) ([]models.OrderStatusChange, error)
 	GetUnprocessedOrders(ctx context.Context) ([]models.Order, error)
-	UpdateOrderStatus(ctx context.Context, order *models.Order) error
+	UpdateOrderStatus(ctx context.Context, order *models.Order, events ...models.DomainEvent) error
 }
 
 type orderRepo struct {
-	db *sql.DB
+	db     *sql.DB
+	router *DBRouter
 }
 
 func NewOrderRepository(db *sql.DB) OrderRepository {
-	return &orderRepo{db: db}
+	return NewRoutedOrderRepository(NewDBRouter(db, nil, 0))
 }
 
-func (r *orderRepo) SaveOrder(ctx context.Context, order *models.Order) error {
+// NewRoutedOrderRepository returns a repository that writes to the router's
+// primary and sends the per-user order list to its replica.
+func NewRoutedOrderRepository(router *DBRouter) OrderRepository {
+	return &orderRepo{db: router.Primary(), router: router}
+}
+
+func (r *orderRepo) SaveOrder(ctx context.Context, order *models.Order, events ...models.DomainEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.SaveOrder")
+	defer func() { tracing.End(span, err) }()
+	defer func() {
+		if err == nil {
+			r.router.MarkWrite(order.UserID)
+		}
+	}()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			if err != nil {
+				logger.FromContext(ctx).Error("rollback error")
+				return
+			}
+		}
+	}()
+
 	query := `INSERT INTO orders (number, status, accrual, uploaded_at, user_id)
 			  VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		order.Number, order.Status, order.Accrual, order.UploadedAt, order.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = recordStatusChange(ctx, tx, order.Number, order.Status, order.Accrual, order.UploadedAt)
+	if err != nil {
+		return err
+	}
+
+	err = saveDomainEvents(ctx, tx, events)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
 	return err
 }
 
-func (r *orderRepo) GetOrdersByUser(ctx context.Context, userID int64) ([]models.Order, error) {
+// SaveOrders inserts the orders in a single transaction, skipping numbers that
+// are already registered. It returns the owners of the skipped numbers.
+// Events are stored only for orders that were actually inserted, matched by
+// AggregateID.
+func (r *orderRepo) SaveOrders(ctx context.Context, orders []models.Order, events ...models.DomainEvent) (_ map[string]int64, err error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.SaveOrders")
+	defer func() { tracing.End(span, err) }()
+	defer func() {
+		if err == nil {
+			for _, order := range orders {
+				r.router.MarkWrite(order.UserID)
+			}
+		}
+	}()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			if err != nil {
+				logger.FromContext(ctx).Error("rollback error")
+				return
+			}
+		}
+	}()
+
+	insertQuery := `INSERT INTO orders (number, status, accrual, uploaded_at, user_id)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (number) DO NOTHING`
+
+	existing := make(map[string]int64)
+	for _, order := range orders {
+		var res sql.Result
+		res, err = tx.ExecContext(ctx, insertQuery,
+			order.Number, order.Status, order.Accrual, order.UploadedAt, order.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		var affected int64
+		affected, err = res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+
+		if affected == 0 {
+			var ownerID int64
+			err = tx.QueryRowContext(ctx, `SELECT user_id FROM orders WHERE number = $1`, order.Number).Scan(&ownerID)
+			if err != nil {
+				return nil, err
+			}
+			existing[order.Number] = ownerID
+			continue
+		}
+
+		err = recordStatusChange(ctx, tx, order.Number, order.Status, order.Accrual, order.UploadedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	inserted := make([]models.DomainEvent, 0, len(events))
+	for _, event := range events {
+		if _, skipped := existing[event.AggregateID]; !skipped {
+			inserted = append(inserted, event)
+		}
+	}
+	err = saveDomainEvents(ctx, tx, inserted)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+func (r *orderRepo) GetOrdersByUser(ctx context.Context, userID int64) (_ []models.Order, err error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.GetOrdersByUser")
+	defer func() { tracing.End(span, err) }()
+
 	query := `SELECT number, status, accrual, uploaded_at FROM orders
 			  WHERE user_id=$1 ORDER BY uploaded_at DESC`
 
-	rows, err := r.db.QueryContext(ctx, query, userID)
+	var orders []models.Order
+	err = r.router.Read(ctx, userID, func(db *sql.DB) error {
+		orders = nil
+		rows, err := db.QueryContext(ctx, query, userID)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to initiate query", zap.Error(err))
+			return err
+		}
+		defer func(rows *sql.Rows) {
+			err := rows.Close()
+			if err != nil {
+				logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
+			}
+		}(rows)
+
+		for rows.Next() {
+			var order models.Order
+			err := rows.Scan(&order.Number, &order.Status, &order.Accrual, &order.UploadedAt)
+			if err != nil {
+				logger.FromContext(ctx).Error("failed to scan order row", zap.Error(err))
+				return err
+			}
+			orders = append(orders, order)
+		}
+
+		if err := rows.Err(); err != nil {
+			logger.FromContext(ctx).Error("error iterating over orders", zap.Error(err))
+			return err
+		}
+		return nil
+	})
 	if err != nil {
-		logger.Log.Error("failed to initiate query", zap.Error(err))
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *orderRepo) GetOrdersPage(ctx context.Context, userID int64, filter models.OrderFilter, after *models.PageCursor) (_ []models.Order, err error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.GetOrdersPage")
+	defer func() { tracing.End(span, err) }()
+
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{userID}
+
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if len(filter.Statuses) > 0 {
+		conditions = append(conditions, "status = ANY("+addArg(pq.Array(filter.Statuses))+")")
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "uploaded_at >= "+addArg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "uploaded_at < "+addArg(*filter.To))
+	}
+	if after != nil {
+		conditions = append(conditions, "(uploaded_at, number) < ("+addArg(after.Time)+", "+addArg(after.Key)+")")
+	}
+
+	query := `SELECT number, status, accrual, uploaded_at FROM orders
+			  WHERE ` + strings.Join(conditions, " AND ") + `
+			  ORDER BY uploaded_at DESC, number DESC
+			  LIMIT ` + addArg(filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to query orders page", zap.Error(err))
 		return nil, err
 	}
 	defer func(rows *sql.Rows) {
 		err := rows.Close()
 		if err != nil {
-			logger.Log.Error("failed to close rows", zap.Error(err))
+			logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
 		}
 	}(rows)
 
 	var orders []models.Order
 	for rows.Next() {
 		var order models.Order
-		err := rows.Scan(&order.Number, &order.Status, &order.Accrual, &order.UploadedAt)
-		if err != nil {
-			logger.Log.Error("failed to scan order row", zap.Error(err))
+		if err := rows.Scan(&order.Number, &order.Status, &order.Accrual, &order.UploadedAt); err != nil {
+			logger.FromContext(ctx).Error("failed to scan order row", zap.Error(err))
 			return nil, err
 		}
 		orders = append(orders, order)
 	}
 
 	if err := rows.Err(); err != nil {
-		logger.Log.Error("error iterating over orders", zap.Error(err))
+		logger.FromContext(ctx).Error("error iterating over orders page", zap.Error(err))
 		return nil, err
 	}
 
 	return orders, nil
 }
 
-func (r *orderRepo) GetOrderOwner(ctx context.Context, number string) (int64, error) {
+func (r *orderRepo) GetOrderOwner(ctx context.Context, number string) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.GetOrderOwner")
+	defer func() { tracing.End(span, err) }()
+
 	query := `SELECT user_id FROM orders WHERE number=$1`
 	var userID int64
-	err := r.db.QueryRowContext(ctx, query, number).Scan(&userID)
+	err = r.db.QueryRowContext(ctx, query, number).Scan(&userID)
 	if errors.Is(err, sql.ErrNoRows) {
 		return 0, nil
 	}
 	return userID, err
 }
 
-func (r *orderRepo) GetUnprocessedOrders(ctx context.Context) ([]models.Order, error) {
+func (r *orderRepo) GetUnprocessedOrders(ctx context.Context) (_ []models.Order, err error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.GetUnprocessedOrders")
+	defer func() { tracing.End(span, err) }()
+
 	query := `
 			SELECT number, status, accrual, uploaded_at, user_id
 			FROM orders
@@ -92,7 +304,7 @@ func (r *orderRepo) GetUnprocessedOrders(ctx context.Context) ([]models.Order, e
 	defer func(rows *sql.Rows) {
 		err := rows.Close()
 		if err != nil {
-			logger.Log.Error("failed to close rows", zap.Error(err))
+			logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
 		}
 	}(rows)
 
@@ -106,19 +318,131 @@ func (r *orderRepo) GetUnprocessedOrders(ctx context.Context) ([]models.Order, e
 	}
 
 	if err := rows.Err(); err != nil {
-		logger.Log.Error("error iterating over unprocessed orders", zap.Error(err))
+		logger.FromContext(ctx).Error("error iterating over unprocessed orders", zap.Error(err))
 		return nil, err
 	}
 
 	return orders, nil
 }
 
-func (r *orderRepo) UpdateOrderStatus(ctx context.Context, order *models.Order) error {
+// UpdateOrderStatus stores the new status and accrual together with a history
+// entry and the given events. When neither changed the call is a no-op: no
+// history is recorded, so repeated polls of a pending order add no noise, and
+// the events are dropped.
+func (r *orderRepo) UpdateOrderStatus(ctx context.Context, order *models.Order, events ...models.DomainEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.UpdateOrderStatus")
+	defer func() { tracing.End(span, err) }()
+	defer func() {
+		if err == nil {
+			r.router.MarkWrite(order.UserID)
+		}
+	}()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			if err != nil {
+				logger.FromContext(ctx).Error("rollback error")
+				return
+			}
+		}
+	}()
+
 	query := `
 		UPDATE orders
 		SET status = $1, accrual = $2
-		WHERE number = $3
+		WHERE number = $3 AND (status IS DISTINCT FROM $1 OR accrual IS DISTINCT FROM $2)
+	`
+	res, err := tx.ExecContext(ctx, query, order.Status, order.Accrual, order.Number)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected > 0 {
+		err = recordStatusChange(ctx, tx, order.Number, order.Status, order.Accrual, time.Now())
+		if err != nil {
+			return err
+		}
+
+		err = saveDomainEvents(ctx, tx, events)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	return err
+}
+
+func (r *orderRepo) GetOrder(ctx context.Context, number string) (_ *models.Order, err error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.GetOrder")
+	defer func() { tracing.End(span, err) }()
+
+	query := `SELECT number, status, accrual, uploaded_at, user_id FROM orders WHERE number = $1`
+
+	var order models.Order
+	err = r.db.QueryRowContext(ctx, query, number).
+		Scan(&order.Number, &order.Status, &order.Accrual, &order.UploadedAt, &order.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *orderRepo) GetOrderStatusHistory(ctx context.Context, number string) (_ []models.OrderStatusChange, err error) {
+	ctx, span := tracing.Start(ctx, "OrderRepository.GetOrderStatusHistory")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+		SELECT status, accrual, changed_at
+		FROM order_status_history
+		WHERE order_number = $1
+		ORDER BY changed_at, id
 	`
-	_, err := r.db.ExecContext(ctx, query, order.Status, order.Accrual, order.Number)
+	rows, err := r.db.QueryContext(ctx, query, number)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to query order status history", zap.Error(err))
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var history []models.OrderStatusChange
+	for rows.Next() {
+		var c models.OrderStatusChange
+		if err := rows.Scan(&c.Status, &c.Accrual, &c.ChangedAt); err != nil {
+			logger.FromContext(ctx).Error("failed to scan order status change", zap.Error(err))
+			return nil, err
+		}
+		history = append(history, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("error iterating over order status history", zap.Error(err))
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func recordStatusChange(ctx context.Context, tx *sql.Tx, number, status string, accrual *float64, changedAt time.Time) error {
+	query := `INSERT INTO order_status_history (order_number, status, accrual, changed_at) VALUES ($1, $2, $3, $4)`
+	_, err := tx.ExecContext(ctx, query, number, status, accrual, changedAt)
 	return err
 }
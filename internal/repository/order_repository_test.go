@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/a2sh3r/gophermart/internal/apperrors"
 	"github.com/a2sh3r/gophermart/internal/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -385,3 +386,90 @@ func TestOrderRepo_UpdateOrderStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestOrderRepo_GetOrdersPage(t *testing.T) {
+	r := NewOrderRepository(testDB)
+	ctx := context.Background()
+
+	setupOrderTestData(t, testDB)
+
+	first, err := r.GetOrdersPage(ctx, 1, models.OrderFilter{Limit: 2}, nil)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.Equal(t, "1111111111", first[0].Number)
+	assert.Equal(t, "0987654321", first[1].Number)
+
+	last := first[1]
+	second, err := r.GetOrdersPage(ctx, 1, models.OrderFilter{Limit: 2}, &models.PageCursor{Time: last.UploadedAt, Key: last.Number})
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	assert.Equal(t, "1234567890", second[0].Number)
+
+	byStatus, err := r.GetOrdersPage(ctx, 1, models.OrderFilter{Statuses: []string{"NEW", "PROCESSED"}, Limit: 10}, nil)
+	require.NoError(t, err)
+	assert.Len(t, byStatus, 2)
+
+	from := time.Now().Add(-45 * time.Minute)
+	to := time.Now().Add(-20 * time.Minute)
+	byRange, err := r.GetOrdersPage(ctx, 1, models.OrderFilter{From: &from, To: &to, Limit: 10}, nil)
+	require.NoError(t, err)
+	require.Len(t, byRange, 1)
+	assert.Equal(t, "0987654321", byRange[0].Number)
+}
+
+func TestOrderRepo_GetOrderStatusHistory(t *testing.T) {
+	r := NewOrderRepository(testDB)
+	ctx := context.Background()
+
+	setupOrderTestData(t, testDB)
+
+	order := &models.Order{Number: "4444444444", Status: "NEW", UploadedAt: time.Now(), UserID: 1}
+	require.NoError(t, r.SaveOrder(ctx, order))
+
+	order.Status = "PROCESSING"
+	require.NoError(t, r.UpdateOrderStatus(ctx, order))
+	require.NoError(t, r.UpdateOrderStatus(ctx, order))
+
+	order.Status = "PROCESSED"
+	order.Accrual = float64Ptr(42)
+	require.NoError(t, r.UpdateOrderStatus(ctx, order))
+
+	history, err := r.GetOrderStatusHistory(ctx, "4444444444")
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, "NEW", history[0].Status)
+	assert.Equal(t, "PROCESSING", history[1].Status)
+	assert.Equal(t, "PROCESSED", history[2].Status)
+	assert.Equal(t, float64Ptr(42), history[2].Accrual)
+
+	found, err := r.GetOrder(ctx, "4444444444")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), found.UserID)
+
+	_, err = r.GetOrder(ctx, "0000000000")
+	assert.ErrorIs(t, err, apperrors.ErrOrderNotFound)
+}
+
+func TestOrderRepo_SaveOrders(t *testing.T) {
+	r := NewOrderRepository(testDB)
+	ctx := context.Background()
+
+	setupOrderTestData(t, testDB)
+
+	now := time.Now()
+	existing, err := r.SaveOrders(ctx, []models.Order{
+		{Number: "5555555555", Status: "NEW", UploadedAt: now, UserID: 1},
+		{Number: "1234567890", Status: "NEW", UploadedAt: now, UserID: 1},
+		{Number: "2222222222", Status: "NEW", UploadedAt: now, UserID: 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"1234567890": 1, "2222222222": 2}, existing)
+
+	owner, err := r.GetOrderOwner(ctx, "5555555555")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), owner)
+
+	history, err := r.GetOrderStatusHistory(ctx, "5555555555")
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"go.uber.org/zap"
+	"slices"
+	"time"
+)
+
+type OutboxRepository interface {
+	ClaimPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]models.DomainEvent, error)
+	MarkEventProcessed(ctx context.Context, eventID int64) error
+	RescheduleEvent(ctx context.Context, eventID int64, lastError string, nextAttemptAt time.Time) error
+	MarkEventFailed(ctx context.Context, eventID int64, lastError string) error
+	DeleteProcessedEventsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+type outboxRepo struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepo{db: db}
+}
+
+// ClaimPendingEvents leases due events in id order, the same way
+// ClaimDueDeliveries does for webhooks.
+func (r *outboxRepo) ClaimPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]models.DomainEvent, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM outbox_events
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE outbox_events e
+		SET next_attempt_at = now() + $2 * interval '1 millisecond'
+		FROM due
+		WHERE e.id = due.id
+		RETURNING e.id, e.event_type, e.user_id, e.aggregate_id, e.payload, e.occurred_at, e.attempts
+	`
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to claim outbox events", zap.Error(err))
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var events []models.DomainEvent
+	for rows.Next() {
+		var e models.DomainEvent
+		if err := rows.Scan(&e.ID, &e.Type, &e.UserID, &e.AggregateID, &e.Payload, &e.OccurredAt, &e.Attempts); err != nil {
+			logger.FromContext(ctx).Error("failed to scan outbox event", zap.Error(err))
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("error iterating over outbox events", zap.Error(err))
+		return nil, err
+	}
+
+	// RETURNING does not preserve the order of the CTE.
+	slices.SortFunc(events, func(a, b models.DomainEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return events, nil
+}
+
+func (r *outboxRepo) MarkEventProcessed(ctx context.Context, eventID int64) error {
+	query := `
+		UPDATE outbox_events
+		SET status = 'processed', attempts = attempts + 1, last_error = '', processed_at = now()
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, eventID)
+	return err
+}
+
+func (r *outboxRepo) RescheduleEvent(ctx context.Context, eventID int64, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2
+		WHERE id = $3
+	`
+	_, err := r.db.ExecContext(ctx, query, lastError, nextAttemptAt, eventID)
+	return err
+}
+
+func (r *outboxRepo) MarkEventFailed(ctx context.Context, eventID int64, lastError string) error {
+	query := `
+		UPDATE outbox_events
+		SET status = 'failed', attempts = attempts + 1, last_error = $1, processed_at = now()
+		WHERE id = $2
+	`
+	_, err := r.db.ExecContext(ctx, query, lastError, eventID)
+	return err
+}
+
+func (r *outboxRepo) DeleteProcessedEventsBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM outbox_events WHERE status = 'processed' AND processed_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// saveDomainEvents appends events to the outbox inside the caller's
+// transaction, so they become visible to the relay only if the change they
+// describe is committed.
+func saveDomainEvents(ctx context.Context, tx *sql.Tx, events []models.DomainEvent) error {
+	query := `
+		INSERT INTO outbox_events (event_type, user_id, aggregate_id, payload, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, event := range events {
+		occurredAt := event.OccurredAt
+		if occurredAt.IsZero() {
+			occurredAt = time.Now()
+		}
+		if _, err := tx.ExecContext(ctx, query, event.Type, event.UserID, event.AggregateID, []byte(event.Payload), occurredAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRepo_WrittenWithChanges(t *testing.T) {
+	r := NewOutboxRepository(testDB)
+	orderRepo := NewOrderRepository(testDB)
+	ctx := context.Background()
+
+	setupUserTestData(t, testDB)
+	_, err := testDB.Exec(`TRUNCATE outbox_events RESTART IDENTITY`)
+	require.NoError(t, err)
+
+	uploaded := func(number string) models.DomainEvent {
+		return models.DomainEvent{
+			Type:        models.EventOrderUploaded,
+			UserID:      1,
+			AggregateID: number,
+			Payload:     []byte(`{"number":"` + number + `"}`),
+		}
+	}
+
+	now := time.Now()
+	require.NoError(t, orderRepo.SaveOrder(ctx,
+		&models.Order{Number: "12345678903", Status: "NEW", UploadedAt: now, UserID: 1}, uploaded("12345678903")))
+
+	_, err = orderRepo.SaveOrders(ctx, []models.Order{
+		{Number: "12345678903", Status: "NEW", UploadedAt: now, UserID: 1},
+		{Number: "79927398713", Status: "NEW", UploadedAt: now, UserID: 1},
+	}, uploaded("12345678903"), uploaded("79927398713"))
+	require.NoError(t, err)
+
+	changed := models.DomainEvent{Type: models.EventOrderStatusChanged, UserID: 1, AggregateID: "79927398713", Payload: []byte(`{}`)}
+	require.NoError(t, orderRepo.UpdateOrderStatus(ctx, &models.Order{Number: "79927398713", Status: "PROCESSING"}, changed))
+	require.NoError(t, orderRepo.UpdateOrderStatus(ctx, &models.Order{Number: "79927398713", Status: "PROCESSING"}, changed))
+
+	events, err := r.ClaimPendingEvents(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, events, 3, "skipped inserts and no-op updates must not emit events")
+	assert.Equal(t, "12345678903", events[0].AggregateID)
+	assert.Equal(t, "79927398713", events[1].AggregateID)
+	assert.Equal(t, models.EventOrderStatusChanged, events[2].Type)
+
+	again, err := r.ClaimPendingEvents(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	require.NoError(t, r.MarkEventProcessed(ctx, events[0].ID))
+	require.NoError(t, r.RescheduleEvent(ctx, events[1].ID, "boom", time.Now().Add(-time.Second)))
+	require.NoError(t, r.MarkEventFailed(ctx, events[2].ID, "boom"))
+
+	again, err = r.ClaimPendingEvents(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, again, 1)
+	assert.Equal(t, events[1].ID, again[0].ID)
+	assert.Equal(t, 1, again[0].Attempts)
+
+	deleted, err := r.DeleteProcessedEventsBefore(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"go.uber.org/zap"
+	"time"
+)
+
+type TwoFactorRepository interface {
+	GetTOTP(ctx context.Context, userID int64) (*models.TOTP, error)
+	SaveTOTPSecret(ctx context.Context, userID int64, secret string) error
+	EnableTOTP(ctx context.Context, userID int64, recoveryCodeHashes []string) error
+	DisableTOTP(ctx context.Context, userID int64) error
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, recoveryCodeHashes []string) error
+	UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+	ReserveTOTPAttempt(ctx context.Context, userID int64, maxFailures int, now, lockUntil time.Time) (bool, error)
+	ResetTOTPFailures(ctx context.Context, userID int64) error
+}
+
+type twoFactorRepo struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) TwoFactorRepository {
+	return &twoFactorRepo{db: db}
+}
+
+func (r *twoFactorRepo) GetTOTP(ctx context.Context, userID int64) (*models.TOTP, error) {
+	query := `SELECT user_id, secret, enabled, last_used_step, enabled_at, failed_attempts, locked_until FROM user_totp WHERE user_id=$1`
+
+	var totp models.TOTP
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&totp.UserID, &totp.Secret, &totp.Enabled, &totp.LastUsedStep, &totp.EnabledAt,
+		&totp.FailedAttempts, &totp.LockedUntil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrTOTPNotConfigured
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get totp", zap.Error(err))
+		return nil, err
+	}
+	return &totp, nil
+}
+
+func (r *twoFactorRepo) SaveTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret, enabled, last_used_step)
+		VALUES ($1, $2, false, 0)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, enabled = false, last_used_step = 0, created_at = now(), enabled_at = NULL,
+		    failed_attempts = 0, locked_until = NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, secret)
+	return err
+}
+
+func (r *twoFactorRepo) EnableTOTP(ctx context.Context, userID int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			if err != nil {
+				logger.FromContext(ctx).Error("rollback error")
+				return
+			}
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, `UPDATE user_totp SET enabled = true, enabled_at = now() WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	err = replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	return err
+}
+
+func (r *twoFactorRepo) DisableTOTP(ctx context.Context, userID int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			if err != nil {
+				logger.FromContext(ctx).Error("rollback error")
+				return
+			}
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	return err
+}
+
+func (r *twoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, userID int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			if err != nil {
+				logger.FromContext(ctx).Error("rollback error")
+				return
+			}
+		}
+	}()
+
+	err = replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	return err
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int64, recoveryCodeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, codeHash := range recoveryCodeHashes {
+		_, err := tx.ExecContext(ctx, `INSERT INTO totp_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, codeHash)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *twoFactorRepo) UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	query := `
+		UPDATE user_totp
+		SET last_used_step = $1
+		WHERE user_id = $2 AND last_used_step < $1
+	`
+	res, err := r.db.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *twoFactorRepo) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	query := `
+		UPDATE totp_recovery_codes
+		SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	res, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// ReserveTOTPAttempt counts a code check before it runs and reports false
+// while verification is locked. The check and the increment are one statement,
+// so concurrent requests cannot each see an unlocked row: the maxFailures-th
+// reservation in a row locks verification until lockUntil.
+func (r *twoFactorRepo) ReserveTOTPAttempt(ctx context.Context, userID int64, maxFailures int, now, lockUntil time.Time) (bool, error) {
+	query := `
+		UPDATE user_totp
+		SET failed_attempts = CASE WHEN failed_attempts + 1 >= $2 THEN 0 ELSE failed_attempts + 1 END,
+		    locked_until = CASE WHEN failed_attempts + 1 >= $2 THEN $4 ELSE NULL END
+		WHERE user_id = $1 AND (locked_until IS NULL OR locked_until <= $3)
+	`
+	res, err := r.db.ExecContext(ctx, query, userID, maxFailures, now, lockUntil)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *twoFactorRepo) ResetTOTPFailures(ctx context.Context, userID int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE user_totp SET failed_attempts = 0, locked_until = NULL WHERE user_id = $1`, userID)
+	return err
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwoFactorRepo_Lifecycle(t *testing.T) {
+	r := NewTwoFactorRepository(testDB)
+	ctx := context.Background()
+
+	setupUserTestData(t, testDB)
+
+	_, err := r.GetTOTP(ctx, 1)
+	assert.ErrorIs(t, err, apperrors.ErrTOTPNotConfigured)
+
+	require.NoError(t, r.SaveTOTPSecret(ctx, 1, "SECRET"))
+
+	totp, err := r.GetTOTP(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "SECRET", totp.Secret)
+	assert.False(t, totp.Enabled)
+
+	require.NoError(t, r.EnableTOTP(ctx, 1, []string{"hash1", "hash2"}))
+
+	totp, err = r.GetTOTP(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, totp.Enabled)
+	assert.NotNil(t, totp.EnabledAt)
+
+	fresh, err := r.UseTOTPStep(ctx, 1, 100)
+	require.NoError(t, err)
+	assert.True(t, fresh)
+
+	fresh, err = r.UseTOTPStep(ctx, 1, 100)
+	require.NoError(t, err)
+	assert.False(t, fresh)
+
+	used, err := r.UseRecoveryCode(ctx, 1, "hash1")
+	require.NoError(t, err)
+	assert.True(t, used)
+
+	used, err = r.UseRecoveryCode(ctx, 1, "hash1")
+	require.NoError(t, err)
+	assert.False(t, used)
+
+	require.NoError(t, r.ReplaceRecoveryCodes(ctx, 1, []string{"hash3"}))
+
+	used, err = r.UseRecoveryCode(ctx, 1, "hash2")
+	require.NoError(t, err)
+	assert.False(t, used)
+
+	now := time.Now().Truncate(time.Microsecond)
+	lockUntil := now.Add(time.Minute)
+
+	reserved, err := r.ReserveTOTPAttempt(ctx, 1, 2, now, lockUntil)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	totp, err = r.GetTOTP(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, totp.FailedAttempts)
+	assert.Nil(t, totp.LockedUntil)
+
+	reserved, err = r.ReserveTOTPAttempt(ctx, 1, 2, now, lockUntil)
+	require.NoError(t, err)
+	assert.True(t, reserved, "the last attempt before the lock is still checked")
+
+	totp, err = r.GetTOTP(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, totp.FailedAttempts)
+	if assert.NotNil(t, totp.LockedUntil) {
+		assert.True(t, lockUntil.Equal(*totp.LockedUntil))
+	}
+
+	reserved, err = r.ReserveTOTPAttempt(ctx, 1, 2, now, lockUntil)
+	require.NoError(t, err)
+	assert.False(t, reserved, "locked")
+
+	reserved, err = r.ReserveTOTPAttempt(ctx, 1, 2, lockUntil, lockUntil.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, reserved, "lock expired")
+
+	require.NoError(t, r.ResetTOTPFailures(ctx, 1))
+
+	totp, err = r.GetTOTP(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, totp.FailedAttempts)
+	assert.Nil(t, totp.LockedUntil)
+
+	require.NoError(t, r.DisableTOTP(ctx, 1))
+
+	_, err = r.GetTOTP(ctx, 1)
+	assert.ErrorIs(t, err, apperrors.ErrTOTPNotConfigured)
+}
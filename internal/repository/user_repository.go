@@ -5,12 +5,18 @@ import (
 	"database/sql"
 	"errors"
 	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
 	"github.com/a2sh3r/gophermart/internal/models"
 )
 
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *models.User) error
 	GetUserByLogin(ctx context.Context, login string) (*models.User, error)
+	GetUserByID(ctx context.Context, userID int64) (*models.User, error)
+	UpdatePassword(ctx context.Context, userID int64, passwordHash string) error
+	UpdatePasswordHash(ctx context.Context, userID int64, oldHash, newHash string) (bool, error)
+	SetRole(ctx context.Context, userID int64, role string) error
+	DeleteUser(ctx context.Context, userID int64, pseudonym string) error
 }
 
 type userRepo struct {
@@ -36,11 +42,16 @@ func (r *userRepo) CreateUser(ctx context.Context, user *models.User) error {
 }
 
 func (r *userRepo) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
-	query := `SELECT id, login, password_hash FROM users WHERE login=$1`
+	query := `
+		SELECT u.id, u.login, u.password_hash, u.token_version, COALESCE(t.enabled, false), u.role
+		FROM users u
+		LEFT JOIN user_totp t ON t.user_id = u.id
+		WHERE u.login=$1
+	`
 	row := r.db.QueryRowContext(ctx, query, login)
 
 	var user models.User
-	err := row.Scan(&user.ID, &user.Login, &user.Password)
+	err := row.Scan(&user.ID, &user.Login, &user.Password, &user.TokenVersion, &user.TOTPEnabled, &user.Role)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, apperrors.ErrUserNotFound
 	}
@@ -49,3 +60,159 @@ func (r *userRepo) GetUserByLogin(ctx context.Context, login string) (*models.Us
 	}
 	return &user, nil
 }
+
+func (r *userRepo) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	query := `
+		SELECT u.id, u.login, u.password_hash, u.token_version, COALESCE(t.enabled, false), u.role
+		FROM users u
+		LEFT JOIN user_totp t ON t.user_id = u.id
+		WHERE u.id=$1
+	`
+	row := r.db.QueryRowContext(ctx, query, userID)
+
+	var user models.User
+	err := row.Scan(&user.ID, &user.Login, &user.Password, &user.TokenVersion, &user.TOTPEnabled, &user.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *userRepo) UpdatePassword(ctx context.Context, userID int64, passwordHash string) error {
+	query := `
+		UPDATE users
+		SET password_hash = $1,
+		    token_version = token_version + 1,
+		    password_changed_at = now()
+		WHERE id = $2
+	`
+	res, err := r.db.ExecContext(ctx, query, passwordHash, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdatePasswordHash replaces oldHash with newHash without touching the token
+// version. It reports false if the user was deleted or the hash changed since
+// oldHash was read, so a rehash never overwrites a newer password.
+func (r *userRepo) UpdatePasswordHash(ctx context.Context, userID int64, oldHash, newHash string) (bool, error) {
+	query := `UPDATE users SET password_hash = $1 WHERE id = $2 AND password_hash = $3 AND deleted_at IS NULL`
+	res, err := r.db.ExecContext(ctx, query, newHash, userID, oldHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *userRepo) SetRole(ctx context.Context, userID int64, role string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET role = $1 WHERE id = $2`, role, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperrors.ErrUserNotFound
+	}
+	return nil
+}
+
+// DeleteUser anonymizes the account in place: the login is replaced with the
+// pseudonym, credentials and second factors are dropped and all sessions and
+// API keys are revoked. Orders, withdrawals and adjustments keep referencing
+// the user id so that accounting records stay intact.
+func (r *userRepo) DeleteUser(ctx context.Context, userID int64, pseudonym string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err := tx.Rollback()
+			if err != nil {
+				logger.FromContext(ctx).Error("rollback error")
+				return
+			}
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET login = $1,
+		    password_hash = '',
+		    role = 'user',
+		    token_version = token_version + 1,
+		    deleted_at = now()
+		WHERE id = $2 AND deleted_at IS NULL
+	`, pseudonym, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		err = apperrors.ErrUserNotFound
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM totp_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE api_keys
+		SET name = '',
+		    revoked_at = COALESCE(revoked_at, now())
+		WHERE user_id = $1
+	`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM webhooks WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM notification_settings WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM notifications WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	return err
+}
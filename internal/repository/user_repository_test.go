@@ -153,3 +153,122 @@ func TestUserRepo_GetUserByLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestUserRepo_GetUserByID(t *testing.T) {
+	r := NewUserRepository(testDB)
+	ctx := context.Background()
+
+	setupUserTestData(t, testDB)
+
+	user, err := r.GetUserByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.NotNil(t, user)
+	assert.Equal(t, "user1", user.Login)
+	assert.Equal(t, int64(0), user.TokenVersion)
+
+	user, err = r.GetUserByID(ctx, 999)
+	assert.ErrorIs(t, err, apperrors.ErrUserNotFound)
+	assert.Nil(t, user)
+}
+
+func TestUserRepo_UpdatePassword(t *testing.T) {
+	r := NewUserRepository(testDB)
+	ctx := context.Background()
+
+	setupUserTestData(t, testDB)
+
+	err := r.UpdatePassword(ctx, 1, "newhash")
+	require.NoError(t, err)
+
+	user, err := r.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "newhash", user.Password)
+	assert.Equal(t, int64(1), user.TokenVersion)
+
+	err = r.UpdatePassword(ctx, 999, "newhash")
+	assert.ErrorIs(t, err, apperrors.ErrUserNotFound)
+}
+
+func TestUserRepo_UpdatePasswordHash(t *testing.T) {
+	r := NewUserRepository(testDB)
+	ctx := context.Background()
+
+	setupUserTestData(t, testDB)
+
+	updated, err := r.UpdatePasswordHash(ctx, 1, "hash1", "$argon2id$rehashed")
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	user, err := r.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "$argon2id$rehashed", user.Password)
+	assert.Equal(t, int64(0), user.TokenVersion)
+
+	require.NoError(t, r.UpdatePassword(ctx, 2, "changed"))
+	updated, err = r.UpdatePasswordHash(ctx, 2, "hash2", "$argon2id$stale")
+	require.NoError(t, err)
+	assert.False(t, updated, "a password changed since login is kept")
+
+	user, err = r.GetUserByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "changed", user.Password)
+
+	require.NoError(t, r.DeleteUser(ctx, 1, "deleted-abc"))
+	updated, err = r.UpdatePasswordHash(ctx, 1, "", "$argon2id$deleted")
+	require.NoError(t, err)
+	assert.False(t, updated, "deleted users are not rehashed")
+}
+
+func TestUserRepo_SetRole(t *testing.T) {
+	r := NewUserRepository(testDB)
+	ctx := context.Background()
+
+	setupUserTestData(t, testDB)
+
+	user, err := r.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleUser, user.Role)
+
+	require.NoError(t, r.SetRole(ctx, 1, models.RoleSupport))
+
+	user, err = r.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleSupport, user.Role)
+
+	assert.Error(t, r.SetRole(ctx, 1, "superuser"))
+	assert.ErrorIs(t, r.SetRole(ctx, 999, models.RoleAdmin), apperrors.ErrUserNotFound)
+}
+
+func TestUserRepo_DeleteUser(t *testing.T) {
+	r := NewUserRepository(testDB)
+	ctx := context.Background()
+
+	setupUserTestData(t, testDB)
+
+	_, err := testDB.Exec(`INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes) VALUES (1, 'partner', 'gm_1', 'h1', '{orders:read}')`)
+	require.NoError(t, err)
+
+	require.NoError(t, r.DeleteUser(ctx, 1, "deleted-abc"))
+
+	_, err = r.GetUserByLogin(ctx, "user1")
+	assert.ErrorIs(t, err, apperrors.ErrUserNotFound)
+
+	user, err := r.GetUserByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "deleted-abc", user.Login)
+	assert.Empty(t, user.Password)
+	assert.Equal(t, int64(1), user.TokenVersion)
+
+	var revoked int
+	err = testDB.QueryRowContext(ctx, `SELECT COUNT(*) FROM api_keys WHERE user_id = 1 AND revoked_at IS NOT NULL`).Scan(&revoked)
+	require.NoError(t, err)
+	assert.Equal(t, 1, revoked)
+
+	var balance float64
+	err = testDB.QueryRowContext(ctx, `SELECT current_balance FROM users WHERE id = 1`).Scan(&balance)
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), balance)
+
+	err = r.DeleteUser(ctx, 1, "deleted-def")
+	assert.ErrorIs(t, err, apperrors.ErrUserNotFound)
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+	"time"
+)
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	GetWebhooksByUser(ctx context.Context, userID int64) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, webhookID int64) error
+	GetWebhookDeliveries(ctx context.Context, userID, webhookID int64, limit int) ([]models.WebhookDelivery, error)
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	MarkDeliveryDelivered(ctx context.Context, deliveryID int64, statusCode int) error
+	RescheduleDelivery(ctx context.Context, deliveryID int64, statusCode int, lastError string, nextAttemptAt time.Time) error
+	MarkDeliveryDead(ctx context.Context, deliveryID int64, statusCode int, lastError string) error
+	EnqueueDeliveries(ctx context.Context, userID int64, eventType string, sourceEventID int64, payload []byte) error
+}
+
+type webhookRepo struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepo{db: db}
+}
+
+func (r *webhookRepo) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	query := `
+		INSERT INTO webhooks (user_id, url, secret, event_types)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRowContext(ctx, query, webhook.UserID, webhook.URL, webhook.Secret, pq.Array(webhook.EventTypes)).
+		Scan(&webhook.ID, &webhook.CreatedAt)
+}
+
+func (r *webhookRepo) GetWebhooksByUser(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	query := `
+		SELECT id, user_id, url, event_types, created_at
+		FROM webhooks
+		WHERE user_id = $1
+		ORDER BY id
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to query webhooks", zap.Error(err))
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var webhooks []models.Webhook
+	for rows.Next() {
+		var wh models.Webhook
+		if err := rows.Scan(&wh.ID, &wh.UserID, &wh.URL, pq.Array(&wh.EventTypes), &wh.CreatedAt); err != nil {
+			logger.FromContext(ctx).Error("failed to scan webhook", zap.Error(err))
+			return nil, err
+		}
+		webhooks = append(webhooks, wh)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("error iterating over webhooks", zap.Error(err))
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (r *webhookRepo) DeleteWebhook(ctx context.Context, userID, webhookID int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`, webhookID, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperrors.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepo) GetWebhookDeliveries(ctx context.Context, userID, webhookID int64, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT d.id, d.webhook_id, d.event_type, d.payload, d.status, d.attempts, d.next_attempt_at,
+		       d.last_status_code, d.last_error, d.created_at, d.delivered_at
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.webhook_id = $1 AND w.user_id = $2
+		ORDER BY d.id DESC
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, webhookID, userID, limit)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to query webhook deliveries", zap.Error(err))
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt); err != nil {
+			logger.FromContext(ctx).Error("failed to scan webhook delivery", zap.Error(err))
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("error iterating over webhook deliveries", zap.Error(err))
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDueDeliveries locks pending deliveries that are due and pushes their
+// next attempt past the lease, so concurrent dispatchers on other instances
+// skip them while this one is sending.
+func (r *webhookRepo) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = now() + $2 * interval '1 millisecond'
+		FROM due, webhooks w
+		WHERE d.id = due.id AND w.id = d.webhook_id
+		RETURNING d.id, d.webhook_id, d.event_type, d.payload, d.status, d.attempts, d.created_at, w.url, w.secret
+	`
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Milliseconds())
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to claim webhook deliveries", zap.Error(err))
+		return nil, err
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var deliveries []models.WebhookDelivery
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.CreatedAt, &d.URL, &d.Secret); err != nil {
+			logger.FromContext(ctx).Error("failed to scan webhook delivery", zap.Error(err))
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.FromContext(ctx).Error("error iterating over claimed deliveries", zap.Error(err))
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (r *webhookRepo) MarkDeliveryDelivered(ctx context.Context, deliveryID int64, statusCode int) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', attempts = attempts + 1, last_status_code = $1, last_error = '', delivered_at = now()
+		WHERE id = $2
+	`
+	_, err := r.db.ExecContext(ctx, query, statusCode, deliveryID)
+	return err
+}
+
+func (r *webhookRepo) RescheduleDelivery(ctx context.Context, deliveryID int64, statusCode int, lastError string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1, last_status_code = $1, last_error = $2, next_attempt_at = $3
+		WHERE id = $4
+	`
+	_, err := r.db.ExecContext(ctx, query, statusCode, lastError, nextAttemptAt, deliveryID)
+	return err
+}
+
+func (r *webhookRepo) MarkDeliveryDead(ctx context.Context, deliveryID int64, statusCode int, lastError string) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'dead', attempts = attempts + 1, last_status_code = $1, last_error = $2
+		WHERE id = $3
+	`
+	_, err := r.db.ExecContext(ctx, query, statusCode, lastError, deliveryID)
+	return err
+}
+
+// EnqueueDeliveries writes one delivery per webhook of the user subscribed to
+// the event type. Deliveries are keyed by the source event, so replaying the
+// same event does not enqueue it twice.
+func (r *webhookRepo) EnqueueDeliveries(ctx context.Context, userID int64, eventType string, sourceEventID int64, payload []byte) error {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_type, payload, source_event_id)
+		SELECT id, $1, $2, $3
+		FROM webhooks
+		WHERE user_id = $4 AND $1 = ANY(event_types)
+		ON CONFLICT (webhook_id, source_event_id) DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, eventType, payload, sourceEventID, userID)
+	return err
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRepo_Lifecycle(t *testing.T) {
+	r := NewWebhookRepository(testDB)
+	ctx := context.Background()
+
+	setupUserTestData(t, testDB)
+
+	webhook := &models.Webhook{
+		UserID:     1,
+		URL:        "https://crm.example.com/hooks",
+		Secret:     "whsec_test",
+		EventTypes: []string{models.WebhookEventBalanceWithdrawn},
+	}
+	require.NoError(t, r.CreateWebhook(ctx, webhook))
+	assert.NotZero(t, webhook.ID)
+
+	webhooks, err := r.GetWebhooksByUser(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Equal(t, webhook.EventTypes, webhooks[0].EventTypes)
+
+	payload := []byte(`{"type":"balance.withdrawn","data":{"order":"2377225624","sum":10}}`)
+	require.NoError(t, r.EnqueueDeliveries(ctx, 1, models.WebhookEventBalanceWithdrawn, 42, payload))
+	require.NoError(t, r.EnqueueDeliveries(ctx, 1, models.WebhookEventBalanceWithdrawn, 42, payload))
+	require.NoError(t, r.EnqueueDeliveries(ctx, 1, models.WebhookEventOrderProcessed, 43, payload))
+	require.NoError(t, r.EnqueueDeliveries(ctx, 2, models.WebhookEventBalanceWithdrawn, 44, payload))
+
+	claimed, err := r.ClaimDueDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, webhook.URL, claimed[0].URL)
+	assert.Equal(t, webhook.Secret, claimed[0].Secret)
+	assert.Equal(t, models.WebhookEventBalanceWithdrawn, claimed[0].EventType)
+
+	assert.JSONEq(t, string(payload), string(claimed[0].Payload))
+
+	claimed, err = r.ClaimDueDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "leased deliveries must not be claimed twice")
+
+	deliveries, err := r.GetWebhookDeliveries(ctx, 1, webhook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	deliveryID := deliveries[0].ID
+
+	require.NoError(t, r.RescheduleDelivery(ctx, deliveryID, 500, "boom", time.Now().Add(-time.Second)))
+	claimed, err = r.ClaimDueDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 1, claimed[0].Attempts)
+
+	require.NoError(t, r.MarkDeliveryDead(ctx, deliveryID, 500, "boom"))
+	deliveries, err = r.GetWebhookDeliveries(ctx, 1, webhook.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, models.DeliveryDead, deliveries[0].Status)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+
+	deliveries, err = r.GetWebhookDeliveries(ctx, 2, webhook.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	assert.ErrorIs(t, r.DeleteWebhook(ctx, 2, webhook.ID), apperrors.ErrWebhookNotFound)
+	require.NoError(t, r.DeleteWebhook(ctx, 1, webhook.ID))
+	assert.ErrorIs(t, r.DeleteWebhook(ctx, 1, webhook.ID), apperrors.ErrWebhookNotFound)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/password"
+	"github.com/a2sh3r/gophermart/internal/repository"
+	"go.uber.org/zap"
+	"time"
+)
+
+const deletedLoginPrefix = "deleted-"
+
+type AccountService interface {
+	Export(ctx context.Context, userID int64) (*models.UserExport, error)
+	Delete(ctx context.Context, userID int64, currentPassword string) error
+}
+
+type accountService struct {
+	userRepo    repository.UserRepository
+	orderRepo   repository.OrderRepository
+	balanceRepo repository.BalanceRepository
+	apiKeyRepo  repository.APIKeyRepository
+	hasher      password.Hasher
+	now         func() time.Time
+}
+
+func NewAccountService(
+	userRepo repository.UserRepository,
+	orderRepo repository.OrderRepository,
+	balanceRepo repository.BalanceRepository,
+	apiKeyRepo repository.APIKeyRepository,
+	hasher password.Hasher,
+) AccountService {
+	return &accountService{
+		userRepo:    userRepo,
+		orderRepo:   orderRepo,
+		balanceRepo: balanceRepo,
+		apiKeyRepo:  apiKeyRepo,
+		hasher:      hasher,
+		now:         time.Now,
+	}
+}
+
+func (s *accountService) Export(ctx context.Context, userID int64) (*models.UserExport, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := s.balanceRepo.GetBalance(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, err := s.orderRepo.GetOrdersByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawals, err := s.balanceRepo.GetWithdrawals(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.balanceRepo.GetBalanceHistory(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.apiKeyRepo.GetAPIKeysByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserExport{
+		ExportedAt: s.now().UTC(),
+		Profile: models.UserInfo{
+			ID:          user.ID,
+			Login:       user.Login,
+			Role:        user.Role,
+			TOTPEnabled: user.TOTPEnabled,
+		},
+		Balance:        balance,
+		Orders:         emptyIfNil(orders),
+		Withdrawals:    emptyIfNil(withdrawals),
+		BalanceHistory: emptyIfNil(history),
+		APIKeys:        emptyIfNil(keys),
+	}, nil
+}
+
+func (s *accountService) Delete(ctx context.Context, userID int64, currentPassword string) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.hasher.Verify(currentPassword, user.Password)
+	if err != nil || !ok {
+		return apperrors.ErrInvalidCredentials
+	}
+
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.DeleteUser(ctx, userID, deletedLoginPrefix+hex.EncodeToString(buf)); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("user account deleted", zap.Int64("userID", userID))
+	return nil
+}
+
+func emptyIfNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/mocks/repository_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/password"
+	"github.com/golang/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAccountService_Delete(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+
+	tests := []struct {
+		name        string
+		password    string
+		mockSetup   func(m *repository_mocks.MockUserRepository)
+		expectedErr error
+	}{
+		{
+			name:     "успешное удаление",
+			password: "password123",
+			mockSetup: func(m *repository_mocks.MockUserRepository) {
+				m.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(&models.User{ID: 1, Login: "user1", Password: string(hashed)}, nil)
+				m.EXPECT().DeleteUser(gomock.Any(), int64(1), gomock.Any()).DoAndReturn(
+					func(_ context.Context, _ int64, pseudonym string) error {
+						if !strings.HasPrefix(pseudonym, deletedLoginPrefix) || strings.Contains(pseudonym, "user1") {
+							t.Errorf("unexpected pseudonym %q", pseudonym)
+						}
+						return nil
+					})
+			},
+		},
+		{
+			name:     "неверный пароль",
+			password: "wrong",
+			mockSetup: func(m *repository_mocks.MockUserRepository) {
+				m.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(&models.User{ID: 1, Password: string(hashed)}, nil)
+			},
+			expectedErr: apperrors.ErrInvalidCredentials,
+		},
+		{
+			name:     "пользователь не найден",
+			password: "password123",
+			mockSetup: func(m *repository_mocks.MockUserRepository) {
+				m.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(nil, apperrors.ErrUserNotFound)
+			},
+			expectedErr: apperrors.ErrUserNotFound,
+		},
+		{
+			name:     "ошибка базы данных",
+			password: "password123",
+			mockSetup: func(m *repository_mocks.MockUserRepository) {
+				m.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(&models.User{ID: 1, Password: string(hashed)}, nil)
+				m.EXPECT().DeleteUser(gomock.Any(), int64(1), gomock.Any()).Return(errors.New("db error"))
+			},
+			expectedErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := repository_mocks.NewMockUserRepository(ctrl)
+			tt.mockSetup(userRepo)
+
+			service := NewAccountService(
+				userRepo,
+				repository_mocks.NewMockOrderRepository(ctrl),
+				repository_mocks.NewMockBalanceRepository(ctrl),
+				repository_mocks.NewMockAPIKeyRepository(ctrl),
+				&password.BcryptHasher{Cost: bcrypt.DefaultCost},
+			)
+			err := service.Delete(context.Background(), 1, tt.password)
+
+			if tt.expectedErr == nil && err != nil {
+				t.Errorf("expected nil error, got %v", err)
+			}
+			if tt.expectedErr != nil && (err == nil || err.Error() != tt.expectedErr.Error()) {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestAccountService_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := repository_mocks.NewMockUserRepository(ctrl)
+	orderRepo := repository_mocks.NewMockOrderRepository(ctrl)
+	balanceRepo := repository_mocks.NewMockBalanceRepository(ctrl)
+	apiKeyRepo := repository_mocks.NewMockAPIKeyRepository(ctrl)
+
+	userRepo.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(&models.User{ID: 1, Login: "user1", Password: "hash", Role: models.RoleUser}, nil)
+	balanceRepo.EXPECT().GetBalance(gomock.Any(), int64(1)).Return(models.Balance{Current: 100, Withdrawn: 20}, nil)
+	orderRepo.EXPECT().GetOrdersByUser(gomock.Any(), int64(1)).Return([]models.Order{{Number: "12345678903", Status: "PROCESSED"}}, nil)
+	balanceRepo.EXPECT().GetWithdrawals(gomock.Any(), int64(1)).Return(nil, nil)
+	balanceRepo.EXPECT().GetBalanceHistory(gomock.Any(), int64(1)).Return([]models.BalanceHistoryEntry{{Type: models.HistoryEntryAccrual, Amount: 100}}, nil)
+	apiKeyRepo.EXPECT().GetAPIKeysByUser(gomock.Any(), int64(1)).Return(nil, nil)
+
+	service := NewAccountService(userRepo, orderRepo, balanceRepo, apiKeyRepo, &password.BcryptHasher{Cost: bcrypt.DefaultCost})
+	export, err := service.Export(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if export.Profile.Login != "user1" || export.Balance.Current != 100 {
+		t.Errorf("unexpected export profile: %+v", export)
+	}
+	if len(export.Orders) != 1 || len(export.BalanceHistory) != 1 {
+		t.Errorf("unexpected export records: %+v", export)
+	}
+	if export.Withdrawals == nil || export.APIKeys == nil {
+		t.Error("empty collections must be exported as empty lists")
+	}
+}
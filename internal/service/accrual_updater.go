@@ -4,8 +4,13 @@ import (
 	"context"
 	"github.com/a2sh3r/gophermart/internal/accrual"
 	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/metrics"
+	"github.com/a2sh3r/gophermart/internal/models"
 	"github.com/a2sh3r/gophermart/internal/repository"
+	"github.com/a2sh3r/gophermart/internal/tracing"
 	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -13,26 +18,64 @@ type AccrualUpdater struct {
 	repo          repository.OrderRepository
 	balanceRepo   repository.BalanceRepository
 	accrualClient accrual.ClientInterface
-	pollInterval  time.Duration
+	events        OrderEventService
+	pollInterval  atomic.Int64
+	workers       atomic.Int32
+	reset         chan struct{}
+	lastSuccess   atomic.Int64
 }
 
-func NewAccrualUpdater(repo repository.OrderRepository, balanceRepo repository.BalanceRepository, client accrual.ClientInterface, interval time.Duration) *AccrualUpdater {
-	return &AccrualUpdater{
+func NewAccrualUpdater(repo repository.OrderRepository, balanceRepo repository.BalanceRepository, client accrual.ClientInterface, events OrderEventService, interval time.Duration) *AccrualUpdater {
+	u := &AccrualUpdater{
 		repo:          repo,
 		balanceRepo:   balanceRepo,
 		accrualClient: client,
-		pollInterval:  interval,
+		events:        events,
+		reset:         make(chan struct{}, 1),
 	}
+	u.pollInterval.Store(int64(interval))
+	u.workers.Store(1)
+	u.lastSuccess.Store(time.Now().UnixNano())
+	return u
+}
+
+// SetPollInterval changes the polling period of a running updater; the new
+// period starts counting from the moment of the change.
+func (u *AccrualUpdater) SetPollInterval(interval time.Duration) {
+	if interval <= 0 || time.Duration(u.pollInterval.Swap(int64(interval))) == interval {
+		return
+	}
+	select {
+	case u.reset <- struct{}{}:
+	default:
+	}
+}
+
+// SetWorkers sets how many orders are checked against the accrual system
+// concurrently; it applies from the next cycle.
+func (u *AccrualUpdater) SetWorkers(workers int) {
+	if workers > 0 {
+		u.workers.Store(int32(workers))
+	}
+}
+
+// LastSuccessfulCycle returns when the updater last managed to load the
+// pending orders. Before the first cycle it is the creation time, which gives
+// a freshly started instance a grace period.
+func (u *AccrualUpdater) LastSuccessfulCycle() time.Time {
+	return time.Unix(0, u.lastSuccess.Load())
 }
 
 func (u *AccrualUpdater) Run(ctx context.Context) {
-	ticker := time.NewTicker(u.pollInterval)
+	ticker := time.NewTicker(time.Duration(u.pollInterval.Load()))
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case <-u.reset:
+			ticker.Reset(time.Duration(u.pollInterval.Load()))
 		case <-ticker.C:
 			u.checkAndUpdateOrders(ctx)
 		}
@@ -40,38 +83,126 @@ func (u *AccrualUpdater) Run(ctx context.Context) {
 }
 
 func (u *AccrualUpdater) checkAndUpdateOrders(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "AccrualUpdater.checkAndUpdateOrders")
+	defer span.End()
+
 	orders, err := u.repo.GetUnprocessedOrders(ctx)
 	if err != nil {
-		logger.Log.Error("failed to get unprocessed orders", zap.Error(err))
+		logger.FromContext(ctx).Error("failed to get unprocessed orders", zap.Error(err))
 		return
 	}
 
-	for _, order := range orders {
-		resp, _, err := u.accrualClient.GetOrderStatus(ctx, order.Number)
-		if err != nil {
-			logger.Log.Warn("failed to get accrual status", zap.String("order", order.Number), zap.Error(err))
-			continue
-		}
+	observeQueue(orders)
+	defer u.lastSuccess.Store(time.Now().UnixNano())
 
-		if resp == nil {
-			continue
+	workers := min(int(u.workers.Load()), len(orders))
+	if workers <= 1 {
+		for _, order := range orders {
+			u.updateOrder(ctx, order)
 		}
+		return
+	}
 
-		order.Status = string(resp.Status)
-		if order.Status == string(accrual.StatusRegistered) {
-			order.Status = StatusNew
-		}
+	queue := make(chan models.Order)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for order := range queue {
+				u.updateOrder(ctx, order)
+			}
+		}()
+	}
+	for _, order := range orders {
+		queue <- order
+	}
+	close(queue)
+	wg.Wait()
+}
 
-		order.Accrual = resp.Accrual
+func (u *AccrualUpdater) updateOrder(ctx context.Context, order models.Order) {
+	resp, _, err := u.accrualClient.GetOrderStatus(ctx, order.Number)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to get accrual status", zap.String("order", order.Number), zap.Error(err))
+		return
+	}
+
+	if resp == nil {
+		return
+	}
+
+	previousStatus := order.Status
+
+	order.Status = string(resp.Status)
+	if order.Status == string(accrual.StatusRegistered) {
+		order.Status = StatusNew
+	}
+
+	order.Accrual = resp.Accrual
+
+	var statusEvents []models.DomainEvent
+	if order.Status != previousStatus {
+		statusEvents = append(statusEvents, newDomainEvent(models.EventOrderStatusChanged, order.UserID, order.Number,
+			models.OrderStatusChangedPayload{
+				Number:         order.Number,
+				PreviousStatus: previousStatus,
+				Status:         order.Status,
+				Accrual:        order.Accrual,
+			}))
+	}
+
+	if err := u.repo.UpdateOrderStatus(ctx, &order, statusEvents...); err != nil {
+		logger.FromContext(ctx).Error("failed to update order", zap.String("order", order.Number), zap.Error(err))
+	} else if order.Status != previousStatus {
+		u.publish(ctx, &models.OrderEvent{
+			UserID:      order.UserID,
+			Type:        models.OrderEventStatusChanged,
+			OrderNumber: order.Number,
+			Status:      order.Status,
+			Accrual:     order.Accrual,
+		})
+	}
 
-		if err := u.repo.UpdateOrderStatus(ctx, &order); err != nil {
-			logger.Log.Error("failed to update order", zap.String("order", order.Number), zap.Error(err))
+	if resp.Status == accrual.StatusProcessed && resp.Accrual != nil {
+		credited := newDomainEvent(models.EventBalanceCredited, order.UserID, order.Number,
+			models.BalanceCreditedPayload{OrderNumber: order.Number, Amount: *resp.Accrual})
+		if err := u.balanceRepo.IncreaseUserBalance(ctx, order.UserID, *resp.Accrual, credited); err != nil {
+			logger.FromContext(ctx).Error("failed to increase balance", zap.Int64("user", order.UserID), zap.Error(err))
+		} else {
+			metrics.PointsCredited.Add(*resp.Accrual)
+			u.publish(ctx, &models.OrderEvent{
+				UserID:      order.UserID,
+				Type:        models.OrderEventBalanceCredited,
+				OrderNumber: order.Number,
+				Accrual:     resp.Accrual,
+			})
 		}
+	}
+}
 
-		if resp.Status == accrual.StatusProcessed && resp.Accrual != nil {
-			if err := u.balanceRepo.IncreaseUserBalance(ctx, order.UserID, *resp.Accrual); err != nil {
-				logger.Log.Error("failed to increase balance", zap.Int64("user", order.UserID), zap.Error(err))
-			}
+// observeQueue reports how many orders still wait for a final accrual status
+// and how long the oldest of them has been waiting.
+func observeQueue(orders []models.Order) {
+	metrics.AccrualQueueDepth.Set(float64(len(orders)))
+
+	var lag time.Duration
+	for _, order := range orders {
+		if order.UploadedAt.IsZero() {
+			continue
 		}
+		if age := time.Since(order.UploadedAt); age > lag {
+			lag = age
+		}
+	}
+	metrics.AccrualQueueLag.Set(lag.Seconds())
+}
+
+func (u *AccrualUpdater) publish(ctx context.Context, event *models.OrderEvent) {
+	if u.events == nil {
+		return
+	}
+	if err := u.events.Publish(ctx, event); err != nil {
+		logger.FromContext(ctx).Error("failed to publish order event", zap.String("type", event.Type), zap.String("order", event.OrderNumber), zap.Error(err))
 	}
 }
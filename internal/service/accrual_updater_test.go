@@ -3,12 +3,15 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/a2sh3r/gophermart/internal/accrual"
 	"github.com/a2sh3r/gophermart/internal/logger"
 	"github.com/a2sh3r/gophermart/internal/mocks/repository_mocks"
+	"github.com/a2sh3r/gophermart/internal/mocks/service_mocks"
 	"github.com/a2sh3r/gophermart/internal/models"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -168,8 +171,8 @@ func TestAccrualUpdater_checkAndUpdateOrders(t *testing.T) {
 					continue
 				}
 
-				mockOrderRepo.EXPECT().UpdateOrderStatus(ctx, gomock.AssignableToTypeOf(&models.Order{})).DoAndReturn(
-					func(_ context.Context, o *models.Order) error {
+				mockOrderRepo.EXPECT().UpdateOrderStatus(ctx, gomock.AssignableToTypeOf(&models.Order{}), gomock.Any()).DoAndReturn(
+					func(_ context.Context, o *models.Order, _ ...models.DomainEvent) error {
 						if err, ok := tt.updateOrderErrors[o.Number]; ok {
 							if string(resp.Status) != o.Status {
 								t.Errorf("expected order status %s, got %s", resp.Status, o.Status)
@@ -192,8 +195,8 @@ func TestAccrualUpdater_checkAndUpdateOrders(t *testing.T) {
 					}).Times(1)
 
 				if resp.Status == accrual.StatusProcessed && resp.Accrual != nil {
-					mockBalanceRepo.EXPECT().IncreaseUserBalance(ctx, order.UserID, *resp.Accrual).DoAndReturn(
-						func(ctx context.Context, userID int64, accrual float64) error {
+					mockBalanceRepo.EXPECT().IncreaseUserBalance(ctx, order.UserID, *resp.Accrual, gomock.Any()).DoAndReturn(
+						func(ctx context.Context, userID int64, accrual float64, _ ...models.DomainEvent) error {
 							if userID != order.UserID {
 								t.Errorf("expected userID %d, got %d", order.UserID, userID)
 							}
@@ -208,7 +211,7 @@ func TestAccrualUpdater_checkAndUpdateOrders(t *testing.T) {
 				}
 			}
 
-			updater := NewAccrualUpdater(mockOrderRepo, mockBalanceRepo, mockAccrualClient, 10*time.Millisecond)
+			updater := NewAccrualUpdater(mockOrderRepo, mockBalanceRepo, mockAccrualClient, nil, 10*time.Millisecond)
 			updater.checkAndUpdateOrders(ctx)
 		})
 	}
@@ -224,7 +227,7 @@ func TestAccrualUpdater_Run(t *testing.T) {
 
 	mockOrderRepo.EXPECT().GetUnprocessedOrders(gomock.Any()).Return([]models.Order{}, nil).AnyTimes()
 
-	updater := NewAccrualUpdater(mockOrderRepo, mockBalanceRepo, mockAccrualClient, 10*time.Millisecond)
+	updater := NewAccrualUpdater(mockOrderRepo, mockBalanceRepo, mockAccrualClient, nil, 10*time.Millisecond)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -247,7 +250,7 @@ func TestAccrualUpdater_Run_WithContextDone(t *testing.T) {
 	mockBalanceRepo := repository_mocks.NewMockBalanceRepository(ctrl)
 	mockAccrualClient := &mockAccrualClient{}
 
-	updater := NewAccrualUpdater(mockOrderRepo, mockBalanceRepo, mockAccrualClient, 1*time.Second)
+	updater := NewAccrualUpdater(mockOrderRepo, mockBalanceRepo, mockAccrualClient, nil, 1*time.Second)
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -257,3 +260,108 @@ func TestAccrualUpdater_Run_WithContextDone(t *testing.T) {
 
 	assert.True(t, true, "Run function completed when context was cancelled")
 }
+
+func TestAccrualUpdater_PublishesEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger.Log = zap.NewNop()
+	ctx := context.Background()
+
+	mockOrderRepo := repository_mocks.NewMockOrderRepository(ctrl)
+	mockBalanceRepo := repository_mocks.NewMockBalanceRepository(ctrl)
+	mockEvents := service_mocks.NewMockOrderEventService(ctrl)
+	mockAccrualClient := &mockAccrualClient{
+		statuses: map[string]*accrual.AccrualResponse{
+			"order1": {Order: "order1", Status: accrual.StatusProcessed, Accrual: floatPtr(100)},
+			"order2": {Order: "order2", Status: accrual.StatusProcessing},
+		},
+	}
+
+	mockOrderRepo.EXPECT().GetUnprocessedOrders(ctx).Return([]models.Order{
+		{Number: "order1", UserID: 1, Status: "PROCESSING"},
+		{Number: "order2", UserID: 2, Status: "PROCESSING"},
+	}, nil)
+	mockOrderRepo.EXPECT().UpdateOrderStatus(ctx, gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	mockBalanceRepo.EXPECT().IncreaseUserBalance(ctx, int64(1), 100.0, gomock.Any()).Return(nil)
+
+	var published []string
+	mockEvents.EXPECT().Publish(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, event *models.OrderEvent) error {
+			published = append(published, event.OrderNumber+":"+event.Type)
+			return nil
+		}).Times(2)
+
+	updater := NewAccrualUpdater(mockOrderRepo, mockBalanceRepo, mockAccrualClient, mockEvents, time.Second)
+	updater.checkAndUpdateOrders(ctx)
+
+	assert.Equal(t, []string{
+		"order1:" + models.OrderEventStatusChanged,
+		"order1:" + models.OrderEventBalanceCredited,
+	}, published)
+}
+
+func TestAccrualUpdater_Workers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger.Log = zap.NewNop()
+	ctx := context.Background()
+
+	orders := make([]models.Order, 0, 10)
+	statuses := make(map[string]*accrual.AccrualResponse, 10)
+	for i := 0; i < 10; i++ {
+		number := fmt.Sprintf("order%d", i)
+		orders = append(orders, models.Order{Number: number, UserID: int64(i), Status: StatusNew})
+		statuses[number] = &accrual.AccrualResponse{Order: number, Status: accrual.StatusProcessing}
+	}
+
+	mockOrderRepo := repository_mocks.NewMockOrderRepository(ctrl)
+	mockBalanceRepo := repository_mocks.NewMockBalanceRepository(ctrl)
+	mockOrderRepo.EXPECT().GetUnprocessedOrders(ctx).Return(orders, nil)
+
+	var mu sync.Mutex
+	updated := make(map[string]bool)
+	mockOrderRepo.EXPECT().UpdateOrderStatus(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, o *models.Order, _ ...models.DomainEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			updated[o.Number] = true
+			return nil
+		}).Times(len(orders))
+
+	updater := NewAccrualUpdater(mockOrderRepo, mockBalanceRepo, &mockAccrualClient{statuses: statuses}, nil, time.Second)
+	updater.SetWorkers(4)
+	updater.checkAndUpdateOrders(ctx)
+
+	assert.Len(t, updated, len(orders))
+}
+
+func TestAccrualUpdater_SetPollInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepo := repository_mocks.NewMockOrderRepository(ctrl)
+	polled := make(chan struct{}, 1)
+	mockOrderRepo.EXPECT().GetUnprocessedOrders(gomock.Any()).DoAndReturn(func(context.Context) ([]models.Order, error) {
+		select {
+		case polled <- struct{}{}:
+		default:
+		}
+		return nil, nil
+	}).AnyTimes()
+
+	updater := NewAccrualUpdater(mockOrderRepo, nil, &mockAccrualClient{}, nil, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go updater.Run(ctx)
+
+	updater.SetPollInterval(10 * time.Millisecond)
+
+	select {
+	case <-polled:
+	case <-time.After(time.Second):
+		t.Fatal("updater did not pick up the new poll interval")
+	}
+}
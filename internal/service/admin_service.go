@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/repository"
+	"go.uber.org/zap"
+	"math"
+	"strings"
+)
+
+type AdminService interface {
+	GetUser(ctx context.Context, userID int64) (*models.UserInfo, error)
+	FindUserByLogin(ctx context.Context, login string) (*models.UserInfo, error)
+	GetUserOrders(ctx context.Context, userID int64) ([]models.Order, error)
+	GetUserBalance(ctx context.Context, userID int64) (models.Balance, error)
+	GetUserWithdrawals(ctx context.Context, userID int64) ([]models.Withdrawal, error)
+	SetUserRole(ctx context.Context, actorID, userID int64, role string) error
+	AdjustBalance(ctx context.Context, actorID, userID int64, req models.BalanceAdjustmentRequest) (*models.BalanceAdjustment, error)
+	GetAdjustments(ctx context.Context, userID int64) ([]models.BalanceAdjustment, error)
+}
+
+type adminService struct {
+	userRepo    repository.UserRepository
+	orderRepo   repository.OrderRepository
+	balanceRepo repository.BalanceRepository
+}
+
+func NewAdminService(userRepo repository.UserRepository, orderRepo repository.OrderRepository, balanceRepo repository.BalanceRepository) AdminService {
+	return &adminService{
+		userRepo:    userRepo,
+		orderRepo:   orderRepo,
+		balanceRepo: balanceRepo,
+	}
+}
+
+func (s *adminService) GetUser(ctx context.Context, userID int64) (*models.UserInfo, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toUserInfo(user), nil
+}
+
+func (s *adminService) FindUserByLogin(ctx context.Context, login string) (*models.UserInfo, error) {
+	user, err := s.userRepo.GetUserByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	return toUserInfo(user), nil
+}
+
+func (s *adminService) GetUserOrders(ctx context.Context, userID int64) ([]models.Order, error) {
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.GetOrdersByUser(ctx, userID)
+}
+
+func (s *adminService) GetUserBalance(ctx context.Context, userID int64) (models.Balance, error) {
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
+		return models.Balance{}, err
+	}
+	return s.balanceRepo.GetBalance(ctx, userID)
+}
+
+func (s *adminService) GetUserWithdrawals(ctx context.Context, userID int64) ([]models.Withdrawal, error) {
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.balanceRepo.GetWithdrawals(ctx, userID)
+}
+
+func (s *adminService) SetUserRole(ctx context.Context, actorID, userID int64, role string) error {
+	if !models.IsValidRole(role) {
+		return apperrors.ErrInvalidRole
+	}
+	if actorID == userID {
+		return apperrors.ErrCannotChangeOwnRole
+	}
+
+	if err := s.userRepo.SetRole(ctx, userID, role); err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("user role changed", zap.Int64("actorID", actorID), zap.Int64("userID", userID), zap.String("role", role))
+	return nil
+}
+
+func (s *adminService) AdjustBalance(ctx context.Context, actorID, userID int64, req models.BalanceAdjustmentRequest) (*models.BalanceAdjustment, error) {
+	reason := strings.TrimSpace(req.Reason)
+	ticketRef := strings.TrimSpace(req.TicketRef)
+	amount := math.Round(req.Amount*100) / 100
+
+	if amount == 0 || reason == "" || ticketRef == "" {
+		return nil, apperrors.ErrInvalidAdjustment
+	}
+
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	adjustment := &models.BalanceAdjustment{
+		UserID:    userID,
+		ActorID:   actorID,
+		Amount:    amount,
+		Reason:    reason,
+		TicketRef: ticketRef,
+	}
+
+	if err := s.balanceRepo.AdjustBalance(ctx, adjustment); err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("balance adjusted",
+		zap.Int64("actorID", actorID),
+		zap.Int64("userID", userID),
+		zap.Float64("amount", amount),
+		zap.String("ticket", ticketRef),
+	)
+	return adjustment, nil
+}
+
+func (s *adminService) GetAdjustments(ctx context.Context, userID int64) ([]models.BalanceAdjustment, error) {
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.balanceRepo.GetAdjustments(ctx, userID)
+}
+
+func toUserInfo(user *models.User) *models.UserInfo {
+	return &models.UserInfo{
+		ID:          user.ID,
+		Login:       user.Login,
+		Role:        user.Role,
+		TOTPEnabled: user.TOTPEnabled,
+	}
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/mocks/repository_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/golang/mock/gomock"
+)
+
+func TestAdminService_GetUserBalance(t *testing.T) {
+	tests := []struct {
+		name        string
+		mockSetup   func(u *repository_mocks.MockUserRepository, b *repository_mocks.MockBalanceRepository)
+		expected    models.Balance
+		expectedErr error
+	}{
+		{
+			name: "пользователь найден",
+			mockSetup: func(u *repository_mocks.MockUserRepository, b *repository_mocks.MockBalanceRepository) {
+				u.EXPECT().GetUserByID(gomock.Any(), int64(5)).Return(&models.User{ID: 5}, nil)
+				b.EXPECT().GetBalance(gomock.Any(), int64(5)).Return(models.Balance{Current: 10, Withdrawn: 2}, nil)
+			},
+			expected: models.Balance{Current: 10, Withdrawn: 2},
+		},
+		{
+			name: "пользователь не найден",
+			mockSetup: func(u *repository_mocks.MockUserRepository, b *repository_mocks.MockBalanceRepository) {
+				u.EXPECT().GetUserByID(gomock.Any(), int64(5)).Return(nil, apperrors.ErrUserNotFound)
+			},
+			expectedErr: apperrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := repository_mocks.NewMockUserRepository(ctrl)
+			orderRepo := repository_mocks.NewMockOrderRepository(ctrl)
+			balanceRepo := repository_mocks.NewMockBalanceRepository(ctrl)
+			tt.mockSetup(userRepo, balanceRepo)
+
+			service := NewAdminService(userRepo, orderRepo, balanceRepo)
+			balance, err := service.GetUserBalance(context.Background(), 5)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if balance != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, balance)
+			}
+		})
+	}
+}
+
+func TestAdminService_FindUserByLogin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := repository_mocks.NewMockUserRepository(ctrl)
+	userRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").
+		Return(&models.User{ID: 1, Login: "user1", Password: "hash", Role: models.RoleSupport, TOTPEnabled: true}, nil)
+
+	service := NewAdminService(userRepo, repository_mocks.NewMockOrderRepository(ctrl), repository_mocks.NewMockBalanceRepository(ctrl))
+
+	info, err := service.FindUserByLogin(context.Background(), "user1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := models.UserInfo{ID: 1, Login: "user1", Role: models.RoleSupport, TOTPEnabled: true}
+	if *info != expected {
+		t.Errorf("expected %+v, got %+v", expected, *info)
+	}
+}
+
+func TestAdminService_SetUserRole(t *testing.T) {
+	tests := []struct {
+		name        string
+		actorID     int64
+		userID      int64
+		role        string
+		mockSetup   func(m *repository_mocks.MockUserRepository)
+		expectedErr error
+	}{
+		{
+			name:    "успешная смена роли",
+			actorID: 1,
+			userID:  2,
+			role:    models.RoleSupport,
+			mockSetup: func(m *repository_mocks.MockUserRepository) {
+				m.EXPECT().SetRole(gomock.Any(), int64(2), models.RoleSupport).Return(nil)
+			},
+		},
+		{
+			name:        "неизвестная роль",
+			actorID:     1,
+			userID:      2,
+			role:        "root",
+			mockSetup:   func(m *repository_mocks.MockUserRepository) {},
+			expectedErr: apperrors.ErrInvalidRole,
+		},
+		{
+			name:        "смена собственной роли",
+			actorID:     1,
+			userID:      1,
+			role:        models.RoleUser,
+			mockSetup:   func(m *repository_mocks.MockUserRepository) {},
+			expectedErr: apperrors.ErrCannotChangeOwnRole,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := repository_mocks.NewMockUserRepository(ctrl)
+			tt.mockSetup(userRepo)
+
+			service := NewAdminService(userRepo, repository_mocks.NewMockOrderRepository(ctrl), repository_mocks.NewMockBalanceRepository(ctrl))
+			err := service.SetUserRole(context.Background(), tt.actorID, tt.userID, tt.role)
+
+			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr == nil && err != nil {
+				t.Errorf("expected nil error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAdminService_AdjustBalance(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         models.BalanceAdjustmentRequest
+		mockSetup   func(u *repository_mocks.MockUserRepository, b *repository_mocks.MockBalanceRepository)
+		expectedErr error
+	}{
+		{
+			name: "начисление",
+			req:  models.BalanceAdjustmentRequest{Amount: 50.005, Reason: " compensation ", TicketRef: "SUP-1"},
+			mockSetup: func(u *repository_mocks.MockUserRepository, b *repository_mocks.MockBalanceRepository) {
+				u.EXPECT().GetUserByID(gomock.Any(), int64(2)).Return(&models.User{ID: 2}, nil)
+				b.EXPECT().AdjustBalance(gomock.Any(), &models.BalanceAdjustment{
+					UserID: 2, ActorID: 1, Amount: 50.01, Reason: "compensation", TicketRef: "SUP-1",
+				}).Return(nil)
+			},
+		},
+		{
+			name: "списание больше баланса",
+			req:  models.BalanceAdjustmentRequest{Amount: -500, Reason: "fraud", TicketRef: "SUP-2"},
+			mockSetup: func(u *repository_mocks.MockUserRepository, b *repository_mocks.MockBalanceRepository) {
+				u.EXPECT().GetUserByID(gomock.Any(), int64(2)).Return(&models.User{ID: 2}, nil)
+				b.EXPECT().AdjustBalance(gomock.Any(), gomock.Any()).Return(apperrors.ErrInsufficientFunds)
+			},
+			expectedErr: apperrors.ErrInsufficientFunds,
+		},
+		{
+			name:        "без причины",
+			req:         models.BalanceAdjustmentRequest{Amount: 10, TicketRef: "SUP-3"},
+			mockSetup:   func(u *repository_mocks.MockUserRepository, b *repository_mocks.MockBalanceRepository) {},
+			expectedErr: apperrors.ErrInvalidAdjustment,
+		},
+		{
+			name:        "без тикета",
+			req:         models.BalanceAdjustmentRequest{Amount: 10, Reason: "bonus"},
+			mockSetup:   func(u *repository_mocks.MockUserRepository, b *repository_mocks.MockBalanceRepository) {},
+			expectedErr: apperrors.ErrInvalidAdjustment,
+		},
+		{
+			name:        "нулевая сумма",
+			req:         models.BalanceAdjustmentRequest{Amount: 0.001, Reason: "bonus", TicketRef: "SUP-4"},
+			mockSetup:   func(u *repository_mocks.MockUserRepository, b *repository_mocks.MockBalanceRepository) {},
+			expectedErr: apperrors.ErrInvalidAdjustment,
+		},
+		{
+			name: "пользователь не найден",
+			req:  models.BalanceAdjustmentRequest{Amount: 10, Reason: "bonus", TicketRef: "SUP-5"},
+			mockSetup: func(u *repository_mocks.MockUserRepository, b *repository_mocks.MockBalanceRepository) {
+				u.EXPECT().GetUserByID(gomock.Any(), int64(2)).Return(nil, apperrors.ErrUserNotFound)
+			},
+			expectedErr: apperrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := repository_mocks.NewMockUserRepository(ctrl)
+			balanceRepo := repository_mocks.NewMockBalanceRepository(ctrl)
+			tt.mockSetup(userRepo, balanceRepo)
+
+			service := NewAdminService(userRepo, repository_mocks.NewMockOrderRepository(ctrl), balanceRepo)
+			_, err := service.AdjustBalance(context.Background(), 1, 2, tt.req)
+
+			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr == nil && err != nil {
+				t.Errorf("expected nil error, got %v", err)
+			}
+		})
+	}
+}
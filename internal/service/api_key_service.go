@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/repository"
+	"go.uber.org/zap"
+	"strings"
+)
+
+const (
+	apiKeyPrefix    = "gm_"
+	apiKeyMaxName   = 100
+	apiKeySecretLen = 24
+)
+
+type APIKeyService interface {
+	Create(ctx context.Context, userID int64, req models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error)
+	List(ctx context.Context, userID int64) ([]models.APIKey, error)
+	Revoke(ctx context.Context, userID, keyID int64) error
+	Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error)
+}
+
+type apiKeyService struct {
+	repo repository.APIKeyRepository
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{repo: repo}
+}
+
+func (s *apiKeyService) Create(ctx context.Context, userID int64, req models.CreateAPIKeyRequest) (*models.CreateAPIKeyResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > apiKeyMaxName {
+		return nil, apperrors.ErrInvalidRequest
+	}
+
+	scopes, err := normalizeScopes(req.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, apiKeySecretLen)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	rawKey := apiKeyPrefix + hex.EncodeToString(buf)
+
+	key := &models.APIKey{
+		UserID:  userID,
+		Name:    name,
+		Prefix:  rawKey[:len(apiKeyPrefix)+8],
+		KeyHash: hashAPIKey(rawKey),
+		Scopes:  scopes,
+	}
+
+	if err := s.repo.CreateAPIKey(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &models.CreateAPIKeyResponse{APIKey: *key, Key: rawKey}, nil
+}
+
+func (s *apiKeyService) List(ctx context.Context, userID int64) ([]models.APIKey, error) {
+	return s.repo.GetAPIKeysByUser(ctx, userID)
+}
+
+func (s *apiKeyService) Revoke(ctx context.Context, userID, keyID int64) error {
+	return s.repo.RevokeAPIKey(ctx, userID, keyID)
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, apperrors.ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.GetAPIKeyByHash(ctx, hashAPIKey(rawKey))
+	if err != nil {
+		return nil, apperrors.ErrInvalidAPIKey
+	}
+	if key.RevokedAt != nil {
+		return nil, apperrors.ErrInvalidAPIKey
+	}
+
+	if err := s.repo.TouchAPIKey(ctx, key.ID); err != nil {
+		logger.FromContext(ctx).Warn("failed to update api key last use", zap.Int64("keyID", key.ID), zap.Error(err))
+	}
+
+	return key, nil
+}
+
+func normalizeScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", apperrors.ErrInvalidScope)
+	}
+
+	seen := make(map[string]struct{}, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !isKnownScope(scope) {
+			return nil, fmt.Errorf("%w: %q", apperrors.ErrInvalidScope, scope)
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		result = append(result, scope)
+	}
+
+	return result, nil
+}
+
+func isKnownScope(scope string) bool {
+	for _, known := range models.APIKeyScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/mocks/repository_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/golang/mock/gomock"
+)
+
+func TestAPIKeyService_Create(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         models.CreateAPIKeyRequest
+		mockSetup   func(m *repository_mocks.MockAPIKeyRepository)
+		expectedErr error
+	}{
+		{
+			name: "успешное создание",
+			req:  models.CreateAPIKeyRequest{Name: "partner", Scopes: []string{models.ScopeOrdersWrite, models.ScopeOrdersWrite}},
+			mockSetup: func(m *repository_mocks.MockAPIKeyRepository) {
+				m.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key *models.APIKey) error {
+					key.ID = 1
+					return nil
+				})
+			},
+		},
+		{
+			name:        "неизвестный scope",
+			req:         models.CreateAPIKeyRequest{Name: "partner", Scopes: []string{"admin:all"}},
+			mockSetup:   func(m *repository_mocks.MockAPIKeyRepository) {},
+			expectedErr: apperrors.ErrInvalidScope,
+		},
+		{
+			name:        "без scope",
+			req:         models.CreateAPIKeyRequest{Name: "partner"},
+			mockSetup:   func(m *repository_mocks.MockAPIKeyRepository) {},
+			expectedErr: apperrors.ErrInvalidScope,
+		},
+		{
+			name:        "пустое имя",
+			req:         models.CreateAPIKeyRequest{Name: "  ", Scopes: []string{models.ScopeOrdersRead}},
+			mockSetup:   func(m *repository_mocks.MockAPIKeyRepository) {},
+			expectedErr: apperrors.ErrInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repository_mocks.NewMockAPIKeyRepository(ctrl)
+			tt.mockSetup(repo)
+
+			service := NewAPIKeyService(repo)
+			resp, err := service.Create(context.Background(), 1, tt.req)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.HasPrefix(resp.Key, apiKeyPrefix) {
+				t.Errorf("unexpected key format: %s", resp.Key)
+			}
+			if resp.KeyHash != hashAPIKey(resp.Key) {
+				t.Error("stored hash does not match issued key")
+			}
+			if !strings.HasPrefix(resp.Key, resp.Prefix) {
+				t.Error("prefix must be the beginning of the key")
+			}
+			if len(resp.Scopes) != 1 {
+				t.Errorf("expected duplicate scopes to be collapsed, got %v", resp.Scopes)
+			}
+		})
+	}
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	revokedAt := time.Now()
+
+	tests := []struct {
+		name        string
+		rawKey      string
+		mockSetup   func(m *repository_mocks.MockAPIKeyRepository)
+		expectedErr error
+	}{
+		{
+			name:   "действующий ключ",
+			rawKey: "gm_valid",
+			mockSetup: func(m *repository_mocks.MockAPIKeyRepository) {
+				m.EXPECT().GetAPIKeyByHash(gomock.Any(), hashAPIKey("gm_valid")).Return(&models.APIKey{ID: 1, UserID: 2}, nil)
+				m.EXPECT().TouchAPIKey(gomock.Any(), int64(1)).Return(nil)
+			},
+		},
+		{
+			name:   "отозванный ключ",
+			rawKey: "gm_revoked",
+			mockSetup: func(m *repository_mocks.MockAPIKeyRepository) {
+				m.EXPECT().GetAPIKeyByHash(gomock.Any(), hashAPIKey("gm_revoked")).Return(&models.APIKey{ID: 1, RevokedAt: &revokedAt}, nil)
+			},
+			expectedErr: apperrors.ErrInvalidAPIKey,
+		},
+		{
+			name:   "неизвестный ключ",
+			rawKey: "gm_unknown",
+			mockSetup: func(m *repository_mocks.MockAPIKeyRepository) {
+				m.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Return(nil, apperrors.ErrAPIKeyNotFound)
+			},
+			expectedErr: apperrors.ErrInvalidAPIKey,
+		},
+		{
+			name:        "неверный формат",
+			rawKey:      "something",
+			mockSetup:   func(m *repository_mocks.MockAPIKeyRepository) {},
+			expectedErr: apperrors.ErrInvalidAPIKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repository_mocks.NewMockAPIKeyRepository(ctrl)
+			tt.mockSetup(repo)
+
+			service := NewAPIKeyService(repo)
+			key, err := service.Authenticate(context.Background(), tt.rawKey)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if key.UserID != 2 {
+				t.Errorf("expected user 2, got %d", key.UserID)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package service
+
+import "time"
+
+// exponentialBackoff returns base doubled for every attempt after the first,
+// capped at limit.
+func exponentialBackoff(attempt int, base, limit time.Duration) time.Duration {
+	backoff := base
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if backoff >= limit {
+			return limit
+		}
+	}
+	return backoff
+}
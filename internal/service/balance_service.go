@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/metrics"
 	"github.com/a2sh3r/gophermart/internal/models"
 	"github.com/a2sh3r/gophermart/internal/repository"
+	"github.com/a2sh3r/gophermart/internal/tracing"
 	"github.com/a2sh3r/gophermart/internal/utils"
 	"time"
 )
@@ -13,6 +16,8 @@ type BalanceService interface {
 	GetUserBalance(ctx context.Context, userID int64) (models.Balance, error)
 	Withdraw(ctx context.Context, userID int64, withdrawal models.WithdrawalRequest) error
 	GetWithdrawals(ctx context.Context, userID int64) ([]models.Withdrawal, error)
+	ListWithdrawals(ctx context.Context, userID int64, filter models.WithdrawalFilter) (*models.WithdrawalPage, error)
+	GetHistory(ctx context.Context, userID int64) ([]models.BalanceHistoryEntry, error)
 }
 
 type balanceService struct {
@@ -23,11 +28,17 @@ func NewBalanceService(repo repository.BalanceRepository) BalanceService {
 	return &balanceService{repo: repo}
 }
 
-func (s *balanceService) GetUserBalance(ctx context.Context, userID int64) (models.Balance, error) {
+func (s *balanceService) GetUserBalance(ctx context.Context, userID int64) (_ models.Balance, err error) {
+	ctx, span := tracing.Start(ctx, "BalanceService.GetUserBalance")
+	defer func() { tracing.End(span, err) }()
+
 	return s.repo.GetBalance(ctx, userID)
 }
 
-func (s *balanceService) Withdraw(ctx context.Context, userID int64, withdrawalReq models.WithdrawalRequest) error {
+func (s *balanceService) Withdraw(ctx context.Context, userID int64, withdrawalReq models.WithdrawalRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "BalanceService.Withdraw")
+	defer func() { tracing.End(span, err) }()
+
 	if !utils.IsValidLuhn(withdrawalReq.Order) {
 		return apperrors.ErrInvalidOrderNumber
 	}
@@ -52,9 +63,69 @@ func (s *balanceService) Withdraw(ctx context.Context, userID int64, withdrawalR
 		UserID:    userID,
 	}
 
-	return s.repo.Withdraw(ctx, withdrawal)
+	err = s.repo.Withdraw(ctx, withdrawal, newDomainEvent(models.EventWithdrawalMade, userID, withdrawal.Order,
+		models.WithdrawalMadePayload{
+			OrderNumber: withdrawal.Order,
+			Sum:         withdrawal.Sum,
+			ProcessedAt: withdrawal.Processed,
+		}))
+	if err != nil {
+		return err
+	}
+
+	metrics.PointsWithdrawn.Add(withdrawal.Sum)
+	return nil
 }
 
-func (s *balanceService) GetWithdrawals(ctx context.Context, userID int64) ([]models.Withdrawal, error) {
+func (s *balanceService) GetWithdrawals(ctx context.Context, userID int64) (_ []models.Withdrawal, err error) {
+	ctx, span := tracing.Start(ctx, "BalanceService.GetWithdrawals")
+	defer func() { tracing.End(span, err) }()
+
 	return s.repo.GetWithdrawals(ctx, userID)
 }
+
+func (s *balanceService) ListWithdrawals(ctx context.Context, userID int64, filter models.WithdrawalFilter) (_ *models.WithdrawalPage, err error) {
+	ctx, span := tracing.Start(ctx, "BalanceService.ListWithdrawals")
+	defer func() { tracing.End(span, err) }()
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", apperrors.ErrInvalidFilter)
+	}
+
+	limit, err := pageLimit(filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.repo.GetWithdrawalTotals(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Limit = limit + 1
+	withdrawals, err := s.repo.GetWithdrawalsPage(ctx, userID, filter, after)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.WithdrawalPage{Withdrawals: withdrawals, Totals: totals}
+	if len(withdrawals) > limit {
+		page.Withdrawals = withdrawals[:limit]
+		last := page.Withdrawals[limit-1]
+		page.NextCursor = encodeCursor(models.PageCursor{Time: last.Processed, Key: last.Order})
+	}
+
+	return page, nil
+}
+
+func (s *balanceService) GetHistory(ctx context.Context, userID int64) (_ []models.BalanceHistoryEntry, err error) {
+	ctx, span := tracing.Start(ctx, "BalanceService.GetHistory")
+	defer func() { tracing.End(span, err) }()
+
+	return s.repo.GetBalanceHistory(ctx, userID)
+}
@@ -46,12 +46,16 @@ func TestBalanceService_Withdraw(t *testing.T) {
 				m.EXPECT().GetBalance(ctx, int64(1)).Return(models.Balance{Current: 100}, nil).Times(1)
 			},
 			mockWithdraw: func(m *repository_mocks.MockBalanceRepository) {
-				m.EXPECT().Withdraw(ctx, gomock.AssignableToTypeOf(models.Withdrawal{})).DoAndReturn(
-					func(_ context.Context, w models.Withdrawal) error {
+				m.EXPECT().Withdraw(ctx, gomock.AssignableToTypeOf(models.Withdrawal{}), gomock.Any()).DoAndReturn(
+					func(_ context.Context, w models.Withdrawal, events ...models.DomainEvent) error {
 						assert.Equal(t, int64(1), w.UserID)
 						assert.Equal(t, validOrder(), w.Order)
 						assert.Equal(t, float64(50), w.Sum)
 						assert.WithinDuration(t, time.Now(), w.Processed, time.Second)
+						if assert.Len(t, events, 1) {
+							assert.Equal(t, models.EventWithdrawalMade, events[0].Type)
+							assert.Equal(t, validOrder(), events[0].AggregateID)
+						}
 						return nil
 					}).Times(1)
 			},
@@ -91,6 +95,21 @@ func TestBalanceService_Withdraw(t *testing.T) {
 			mockWithdraw: func(m *repository_mocks.MockBalanceRepository) {},
 			wantErr:      apperrors.ErrInsufficientFunds,
 		},
+		{
+			name:   "устаревший баланс с реплики",
+			userID: 3,
+			withdrawalReq: models.WithdrawalRequest{
+				Order: validOrder(),
+				Sum:   150,
+			},
+			mockGetBalance: func(m *repository_mocks.MockBalanceRepository) {
+				m.EXPECT().GetBalance(ctx, int64(3)).Return(models.Balance{Current: 200}, nil).Times(1)
+			},
+			mockWithdraw: func(m *repository_mocks.MockBalanceRepository) {
+				m.EXPECT().Withdraw(ctx, gomock.AssignableToTypeOf(models.Withdrawal{}), gomock.Any()).Return(apperrors.ErrInsufficientFunds).Times(1)
+			},
+			wantErr: apperrors.ErrInsufficientFunds,
+		},
 		{
 			name:           "некорректная сумма для вывода (<=0)",
 			userID:         4,
@@ -110,7 +129,7 @@ func TestBalanceService_Withdraw(t *testing.T) {
 				m.EXPECT().GetBalance(ctx, int64(5)).Return(models.Balance{Current: 50}, nil).Times(1)
 			},
 			mockWithdraw: func(m *repository_mocks.MockBalanceRepository) {
-				m.EXPECT().Withdraw(ctx, gomock.AssignableToTypeOf(models.Withdrawal{})).Return(errors.New("write error")).Times(1)
+				m.EXPECT().Withdraw(ctx, gomock.AssignableToTypeOf(models.Withdrawal{}), gomock.Any()).Return(errors.New("write error")).Times(1)
 			},
 			wantErr: errors.New("write error"),
 		},
@@ -249,3 +268,84 @@ func TestBalanceService_GetWithdrawals(t *testing.T) {
 		})
 	}
 }
+
+func TestBalanceService_ListWithdrawals(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	withdrawals := []models.Withdrawal{
+		{Order: "2", Sum: 10, Processed: now},
+		{Order: "1", Sum: 20, Processed: now.Add(-time.Hour)},
+	}
+	totals := models.WithdrawalTotals{Count: 2, Sum: 30}
+	from := now.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		filter      models.WithdrawalFilter
+		mockSetup   func(m *repository_mocks.MockBalanceRepository)
+		wantCount   int
+		wantNext    bool
+		expectedErr error
+	}{
+		{
+			name:   "первая страница",
+			filter: models.WithdrawalFilter{Limit: 1, From: &from},
+			mockSetup: func(m *repository_mocks.MockBalanceRepository) {
+				m.EXPECT().GetWithdrawalTotals(gomock.Any(), int64(1), models.WithdrawalFilter{Limit: 1, From: &from}).Return(totals, nil)
+				m.EXPECT().GetWithdrawalsPage(gomock.Any(), int64(1), models.WithdrawalFilter{Limit: 2, From: &from}, (*models.PageCursor)(nil)).Return(withdrawals, nil)
+			},
+			wantCount: 1,
+			wantNext:  true,
+		},
+		{
+			name:   "по возрастанию",
+			filter: models.WithdrawalFilter{Ascending: true},
+			mockSetup: func(m *repository_mocks.MockBalanceRepository) {
+				m.EXPECT().GetWithdrawalTotals(gomock.Any(), int64(1), gomock.Any()).Return(totals, nil)
+				m.EXPECT().GetWithdrawalsPage(gomock.Any(), int64(1), models.WithdrawalFilter{Ascending: true, Limit: DefaultPageLimit + 1}, gomock.Any()).Return(withdrawals, nil)
+			},
+			wantCount: 2,
+		},
+		{
+			name:        "пустой диапазон",
+			filter:      models.WithdrawalFilter{From: &now, To: &from},
+			mockSetup:   func(m *repository_mocks.MockBalanceRepository) {},
+			expectedErr: apperrors.ErrInvalidFilter,
+		},
+		{
+			name:        "некорректный курсор",
+			filter:      models.WithdrawalFilter{Cursor: "bm9wZQ"},
+			mockSetup:   func(m *repository_mocks.MockBalanceRepository) {},
+			expectedErr: apperrors.ErrInvalidCursor,
+		},
+		{
+			name:   "ошибка базы данных",
+			filter: models.WithdrawalFilter{Limit: 10},
+			mockSetup: func(m *repository_mocks.MockBalanceRepository) {
+				m.EXPECT().GetWithdrawalTotals(gomock.Any(), int64(1), gomock.Any()).Return(models.WithdrawalTotals{}, errors.New("db error"))
+			},
+			expectedErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repository_mocks.NewMockBalanceRepository(ctrl)
+			tt.mockSetup(repo)
+			service := NewBalanceService(repo)
+
+			page, err := service.ListWithdrawals(context.Background(), 1, tt.filter)
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, page.Withdrawals, tt.wantCount)
+			assert.Equal(t, tt.wantNext, page.NextCursor != "")
+			assert.Equal(t, totals, page.Totals)
+		})
+	}
+}
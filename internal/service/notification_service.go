@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/a2sh3r/gophermart/internal/accrual"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/notify"
+	"github.com/a2sh3r/gophermart/internal/repository"
+	"go.uber.org/zap"
+	"net/mail"
+	"strconv"
+	"strings"
+)
+
+const DefaultLargeWithdrawal = 1000
+
+type NotificationService interface {
+	List(ctx context.Context, userID int64, filter models.NotificationFilter) (*models.NotificationPage, error)
+	UnreadCount(ctx context.Context, userID int64) (int64, error)
+	MarkRead(ctx context.Context, userID, notificationID int64) error
+	MarkAllRead(ctx context.Context, userID int64) error
+	GetSettings(ctx context.Context, userID int64) (models.NotificationSettings, error)
+	UpdateSettings(ctx context.Context, userID int64, settings models.NotificationSettings) (models.NotificationSettings, error)
+	HandleDomainEvent(ctx context.Context, event models.DomainEvent) error
+}
+
+type notificationService struct {
+	repo            repository.NotificationRepository
+	channels        []notify.Channel
+	largeWithdrawal float64
+}
+
+func NewNotificationService(repo repository.NotificationRepository, channels []notify.Channel, largeWithdrawal float64) NotificationService {
+	if largeWithdrawal <= 0 {
+		largeWithdrawal = DefaultLargeWithdrawal
+	}
+	return &notificationService{
+		repo:            repo,
+		channels:        channels,
+		largeWithdrawal: largeWithdrawal,
+	}
+}
+
+func (s *notificationService) List(ctx context.Context, userID int64, filter models.NotificationFilter) (*models.NotificationPage, error) {
+	limit, err := pageLimit(filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		if _, err := strconv.ParseInt(after.Key, 10, 64); err != nil {
+			return nil, apperrors.ErrInvalidCursor
+		}
+	}
+
+	unread, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Limit = limit + 1
+	notifications, err := s.repo.GetNotificationsPage(ctx, userID, filter, after)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.NotificationPage{Notifications: notifications, UnreadCount: unread}
+	if len(notifications) > limit {
+		page.Notifications = notifications[:limit]
+		last := page.Notifications[limit-1]
+		page.NextCursor = encodeCursor(models.PageCursor{Time: last.CreatedAt, Key: strconv.FormatInt(last.ID, 10)})
+	}
+
+	return page, nil
+}
+
+func (s *notificationService) UnreadCount(ctx context.Context, userID int64) (int64, error) {
+	return s.repo.CountUnread(ctx, userID)
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID, notificationID int64) error {
+	return s.repo.MarkRead(ctx, userID, notificationID)
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userID int64) error {
+	_, err := s.repo.MarkAllRead(ctx, userID)
+	return err
+}
+
+func (s *notificationService) GetSettings(ctx context.Context, userID int64) (models.NotificationSettings, error) {
+	return s.repo.GetSettings(ctx, userID)
+}
+
+func (s *notificationService) UpdateSettings(ctx context.Context, userID int64, settings models.NotificationSettings) (models.NotificationSettings, error) {
+	settings.UserID = userID
+	settings.Email = strings.TrimSpace(settings.Email)
+
+	if settings.Email != "" {
+		addr, err := mail.ParseAddress(settings.Email)
+		if err != nil || addr.Name != "" || addr.Address != settings.Email {
+			return models.NotificationSettings{}, apperrors.ErrInvalidEmail
+		}
+	}
+	if settings.EmailEnabled && settings.Email == "" {
+		return models.NotificationSettings{}, fmt.Errorf("%w: email is required to enable email notifications", apperrors.ErrInvalidEmail)
+	}
+
+	if err := s.repo.SaveSettings(ctx, settings); err != nil {
+		return models.NotificationSettings{}, err
+	}
+	return settings, nil
+}
+
+// HandleDomainEvent is the event bus subscriber that writes inbox entries and
+// fans them out to the user's delivery channels. Channel failures are logged
+// and not retried; a replayed event neither duplicates the entry nor resends.
+func (s *notificationService) HandleDomainEvent(ctx context.Context, event models.DomainEvent) error {
+	notification, err := s.notificationFor(event)
+	if err != nil || notification == nil {
+		return err
+	}
+
+	// Nothing is created for a replayed event or a deleted user, and then
+	// nothing is sent either.
+	created, err := s.repo.CreateNotification(ctx, notification)
+	if err != nil || !created {
+		return err
+	}
+
+	if len(s.channels) == 0 {
+		return nil
+	}
+
+	settings, err := s.repo.GetSettings(ctx, notification.UserID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load notification settings", zap.Int64("user", notification.UserID), zap.Error(err))
+		return nil
+	}
+
+	for _, channel := range s.channels {
+		if !channel.Enabled(settings) {
+			continue
+		}
+		if err := channel.Send(ctx, settings, *notification); err != nil {
+			logger.FromContext(ctx).Warn("failed to deliver notification",
+				zap.String("channel", channel.Name()), zap.Int64("notification", notification.ID), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+func (s *notificationService) notificationFor(event models.DomainEvent) (*models.Notification, error) {
+	n := &models.Notification{
+		UserID:        event.UserID,
+		Data:          event.Payload,
+		SourceEventID: event.ID,
+	}
+
+	switch event.Type {
+	case models.EventOrderStatusChanged:
+		var payload models.OrderStatusChangedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, err
+		}
+		switch payload.Status {
+		case StatusProcessed:
+			n.Type = models.NotificationOrderProcessed
+			n.Title = fmt.Sprintf("Order %s processed", payload.Number)
+			if payload.Accrual != nil && *payload.Accrual > 0 {
+				n.Body = fmt.Sprintf("You earned %.2f points for order %s.", *payload.Accrual, payload.Number)
+			} else {
+				n.Body = fmt.Sprintf("Order %s was processed without earning points.", payload.Number)
+			}
+		case string(accrual.StatusInvalid):
+			n.Type = models.NotificationOrderInvalid
+			n.Title = fmt.Sprintf("Order %s rejected", payload.Number)
+			n.Body = fmt.Sprintf("Order %s was rejected by the loyalty system and will not earn points.", payload.Number)
+		default:
+			return nil, nil
+		}
+	case models.EventWithdrawalMade:
+		var payload models.WithdrawalMadePayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, err
+		}
+		if payload.Sum < s.largeWithdrawal {
+			return nil, nil
+		}
+		n.Type = models.NotificationLargeWithdrawal
+		n.Title = "Large withdrawal"
+		n.Body = fmt.Sprintf("%.2f points were withdrawn for order %s. If this wasn't you, change your password and contact support.",
+			payload.Sum, payload.OrderNumber)
+	default:
+		return nil, nil
+	}
+
+	return n, nil
+}
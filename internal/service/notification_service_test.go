@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/mocks/repository_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/notify"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type fakeChannel struct {
+	sent []models.Notification
+	err  error
+}
+
+func (c *fakeChannel) Name() string {
+	return "fake"
+}
+
+func (c *fakeChannel) Enabled(settings models.NotificationSettings) bool {
+	return settings.EmailEnabled
+}
+
+func (c *fakeChannel) Send(_ context.Context, _ models.NotificationSettings, n models.Notification) error {
+	c.sent = append(c.sent, n)
+	return c.err
+}
+
+func TestNotificationService_HandleDomainEvent(t *testing.T) {
+	logger.Log = zap.NewNop()
+
+	processed := models.DomainEvent{ID: 10, Type: models.EventOrderStatusChanged, UserID: 1,
+		Payload: []byte(`{"number":"12345678903","previous_status":"PROCESSING","status":"PROCESSED","accrual":100}`)}
+
+	tests := []struct {
+		name       string
+		event      models.DomainEvent
+		channelErr error
+		mockSetup  func(m *repository_mocks.MockNotificationRepository)
+		wantType   string
+		wantSent   int
+	}{
+		{
+			name:  "заказ обработан — уведомление и письмо",
+			event: processed,
+			mockSetup: func(m *repository_mocks.MockNotificationRepository) {
+				m.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Return(true, nil)
+				m.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.NotificationSettings{Email: "a@b.c", EmailEnabled: true}, nil)
+			},
+			wantType: models.NotificationOrderProcessed,
+			wantSent: 1,
+		},
+		{
+			name:  "повтор события — без повторной отправки",
+			event: processed,
+			mockSetup: func(m *repository_mocks.MockNotificationRepository) {
+				m.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Return(false, nil)
+			},
+			wantType: models.NotificationOrderProcessed,
+		},
+		{
+			name:       "ошибка канала не ломает обработку",
+			event:      processed,
+			channelErr: errors.New("smtp down"),
+			mockSetup: func(m *repository_mocks.MockNotificationRepository) {
+				m.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Return(true, nil)
+				m.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.NotificationSettings{Email: "a@b.c", EmailEnabled: true}, nil)
+			},
+			wantType: models.NotificationOrderProcessed,
+			wantSent: 1,
+		},
+		{
+			name: "заказ отклонён — только inbox",
+			event: models.DomainEvent{ID: 11, Type: models.EventOrderStatusChanged, UserID: 1,
+				Payload: []byte(`{"number":"12345678903","previous_status":"NEW","status":"INVALID"}`)},
+			mockSetup: func(m *repository_mocks.MockNotificationRepository) {
+				m.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Return(true, nil)
+				m.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.NotificationSettings{}, nil)
+			},
+			wantType: models.NotificationOrderInvalid,
+		},
+		{
+			name: "промежуточный статус игнорируется",
+			event: models.DomainEvent{ID: 12, Type: models.EventOrderStatusChanged, UserID: 1,
+				Payload: []byte(`{"number":"12345678903","previous_status":"NEW","status":"PROCESSING"}`)},
+			mockSetup: func(m *repository_mocks.MockNotificationRepository) {},
+		},
+		{
+			name:      "небольшой вывод средств игнорируется",
+			event:     models.DomainEvent{ID: 13, Type: models.EventWithdrawalMade, UserID: 1, Payload: []byte(`{"order":"79927398713","sum":999.99}`)},
+			mockSetup: func(m *repository_mocks.MockNotificationRepository) {},
+		},
+		{
+			name:  "крупный вывод средств",
+			event: models.DomainEvent{ID: 14, Type: models.EventWithdrawalMade, UserID: 1, Payload: []byte(`{"order":"79927398713","sum":1000}`)},
+			mockSetup: func(m *repository_mocks.MockNotificationRepository) {
+				m.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Return(true, nil)
+				m.EXPECT().GetSettings(gomock.Any(), int64(1)).Return(models.NotificationSettings{EmailEnabled: true}, nil)
+			},
+			wantType: models.NotificationLargeWithdrawal,
+			wantSent: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repository_mocks.NewMockNotificationRepository(ctrl)
+			tt.mockSetup(repo)
+
+			channel := &fakeChannel{err: tt.channelErr}
+			svc := NewNotificationService(repo, []notify.Channel{channel}, 1000).(*notificationService)
+
+			n, err := svc.notificationFor(tt.event)
+			assert.NoError(t, err)
+			if tt.wantType == "" {
+				assert.Nil(t, n)
+			} else {
+				assert.Equal(t, tt.wantType, n.Type)
+				assert.Equal(t, tt.event.ID, n.SourceEventID)
+			}
+
+			assert.NoError(t, svc.HandleDomainEvent(context.Background(), tt.event))
+			assert.Len(t, channel.sent, tt.wantSent)
+		})
+	}
+}
+
+func TestNotificationService_UpdateSettings(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    models.NotificationSettings
+		mockSetup   func(m *repository_mocks.MockNotificationRepository)
+		expectedErr error
+	}{
+		{
+			name:     "успешное сохранение",
+			settings: models.NotificationSettings{Email: " user@example.com ", EmailEnabled: true},
+			mockSetup: func(m *repository_mocks.MockNotificationRepository) {
+				m.EXPECT().SaveSettings(gomock.Any(), models.NotificationSettings{UserID: 1, Email: "user@example.com", EmailEnabled: true}).Return(nil)
+			},
+		},
+		{
+			name:        "некорректный адрес",
+			settings:    models.NotificationSettings{Email: "Bob <bob@example.com>"},
+			mockSetup:   func(m *repository_mocks.MockNotificationRepository) {},
+			expectedErr: apperrors.ErrInvalidEmail,
+		},
+		{
+			name:        "включение без адреса",
+			settings:    models.NotificationSettings{EmailEnabled: true},
+			mockSetup:   func(m *repository_mocks.MockNotificationRepository) {},
+			expectedErr: apperrors.ErrInvalidEmail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repository_mocks.NewMockNotificationRepository(ctrl)
+			tt.mockSetup(repo)
+
+			_, err := NewNotificationService(repo, nil, 0).UpdateSettings(context.Background(), 1, tt.settings)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestNotificationService_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository_mocks.NewMockNotificationRepository(ctrl)
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	repo.EXPECT().CountUnread(gomock.Any(), int64(1)).Return(int64(3), nil)
+	repo.EXPECT().GetNotificationsPage(gomock.Any(), int64(1), models.NotificationFilter{UnreadOnly: true, Limit: 3}, nil).
+		Return([]models.Notification{{ID: 9, CreatedAt: created}, {ID: 8, CreatedAt: created}, {ID: 7, CreatedAt: created}}, nil)
+
+	svc := NewNotificationService(repo, nil, 0)
+	page, err := svc.List(context.Background(), 1, models.NotificationFilter{UnreadOnly: true, Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Notifications, 2)
+	assert.Equal(t, int64(3), page.UnreadCount)
+
+	cursor, err := decodeCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, "8", cursor.Key)
+
+	_, err = svc.List(context.Background(), 1, models.NotificationFilter{Cursor: encodeCursor(models.PageCursor{Time: created, Key: "x"})})
+	assert.ErrorIs(t, err, apperrors.ErrInvalidCursor)
+}
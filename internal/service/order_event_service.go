@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"github.com/a2sh3r/gophermart/internal/events"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/repository"
+	"go.uber.org/zap"
+	"time"
+)
+
+const orderEventsBatchSize = 100
+
+type OrderEventService interface {
+	Publish(ctx context.Context, event *models.OrderEvent) error
+	Subscribe(userID int64) *events.Subscription
+	LatestID(ctx context.Context, userID int64) (int64, error)
+	Since(ctx context.Context, userID, afterID int64) ([]models.OrderEvent, error)
+	RunCleanup(ctx context.Context, interval, retention time.Duration)
+}
+
+type orderEventService struct {
+	repo   repository.OrderEventRepository
+	broker *events.Broker
+}
+
+func NewOrderEventService(repo repository.OrderEventRepository, broker *events.Broker) OrderEventService {
+	return &orderEventService{repo: repo, broker: broker}
+}
+
+// Publish stores the event; subscribers are woken by the database
+// notification sent on commit, including those on this instance.
+func (s *orderEventService) Publish(ctx context.Context, event *models.OrderEvent) error {
+	return s.repo.SaveOrderEvent(ctx, event)
+}
+
+func (s *orderEventService) Subscribe(userID int64) *events.Subscription {
+	return s.broker.Subscribe(userID)
+}
+
+func (s *orderEventService) LatestID(ctx context.Context, userID int64) (int64, error) {
+	return s.repo.GetLatestOrderEventID(ctx, userID)
+}
+
+func (s *orderEventService) Since(ctx context.Context, userID, afterID int64) ([]models.OrderEvent, error) {
+	return s.repo.GetOrderEventsSince(ctx, userID, afterID, orderEventsBatchSize)
+}
+
+func (s *orderEventService) RunCleanup(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.repo.DeleteOrderEventsBefore(ctx, time.Now().Add(-retention))
+			if err != nil {
+				logger.FromContext(ctx).Error("failed to delete old order events", zap.Error(err))
+				continue
+			}
+			if deleted > 0 {
+				logger.FromContext(ctx).Info("old order events deleted", zap.Int64("count", deleted))
+			}
+		}
+	}
+}
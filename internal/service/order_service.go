@@ -2,41 +2,64 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"github.com/a2sh3r/gophermart/internal/accrual"
 	"github.com/a2sh3r/gophermart/internal/apperrors"
 	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/metrics"
 	"github.com/a2sh3r/gophermart/internal/models"
 	"github.com/a2sh3r/gophermart/internal/repository"
+	"github.com/a2sh3r/gophermart/internal/tracing"
 	"github.com/a2sh3r/gophermart/internal/utils"
 	"go.uber.org/zap"
 	"time"
 )
 
+var orderStatuses = map[string]struct{}{
+	StatusNew:                        {},
+	string(accrual.StatusProcessing): {},
+	string(accrual.StatusInvalid):    {},
+	StatusProcessed:                  {},
+}
+
 const (
 	StatusNew       = "NEW"
 	StatusProcessed = "PROCESSED"
+
+	DefaultOrderBatchSize = 100
 )
 
 type OrderService interface {
 	UploadOrder(ctx context.Context, number string, userID int64) error
 	GetUserOrders(ctx context.Context, userID int64) ([]models.Order, error)
+	ListUserOrders(ctx context.Context, userID int64, filter models.OrderFilter) (*models.OrderPage, error)
+	GetUserOrder(ctx context.Context, userID int64, number string) (*models.OrderDetail, error)
+	UploadOrders(ctx context.Context, numbers []string, userID int64) ([]models.BatchOrderResult, error)
 }
 
 type orderService struct {
 	repo          repository.OrderRepository
 	balanceRepo   repository.BalanceRepository
 	accrualClient accrual.ClientInterface
+	batchMaxSize  int
 }
 
-func NewOrderService(repo repository.OrderRepository, balanceRepo repository.BalanceRepository, accrualClient accrual.ClientInterface) OrderService {
+func NewOrderService(repo repository.OrderRepository, balanceRepo repository.BalanceRepository, accrualClient accrual.ClientInterface, batchMaxSize int) OrderService {
+	if batchMaxSize <= 0 {
+		batchMaxSize = DefaultOrderBatchSize
+	}
 	return &orderService{
 		repo:          repo,
 		balanceRepo:   balanceRepo,
 		accrualClient: accrualClient,
+		batchMaxSize:  batchMaxSize,
 	}
 }
 
-func (s *orderService) UploadOrder(ctx context.Context, number string, userID int64) error {
+func (s *orderService) UploadOrder(ctx context.Context, number string, userID int64) (err error) {
+	ctx, span := tracing.Start(ctx, "OrderService.UploadOrder")
+	defer func() { tracing.End(span, err) }()
+
 	if !utils.IsValidLuhn(number) {
 		return apperrors.ErrInvalidOrderNumber
 	}
@@ -53,12 +76,12 @@ func (s *orderService) UploadOrder(ctx context.Context, number string, userID in
 		return apperrors.ErrOrderExistsOtherUser
 	}
 
-	logger.Log.Info("trying to get order status from accrual system", zap.String("order", number))
+	logger.FromContext(ctx).Info("trying to get order status from accrual system", zap.String("order", number))
 	accrualResp, statusCode, err := s.accrualClient.GetOrderStatus(ctx, number)
 	if err != nil {
-		logger.Log.Warn("accrual service error", zap.Error(err), zap.Int("statusCode", statusCode))
+		logger.FromContext(ctx).Warn("accrual service error", zap.Error(err), zap.Int("statusCode", statusCode))
 	} else {
-		logger.Log.Info("accrual service response received", zap.Any("response", accrualResp))
+		logger.FromContext(ctx).Info("accrual service response received", zap.Any("response", accrualResp))
 	}
 
 	status := StatusNew
@@ -77,21 +100,177 @@ func (s *orderService) UploadOrder(ctx context.Context, number string, userID in
 		UserID:     userID,
 	}
 
-	if err := s.repo.SaveOrder(ctx, order); err != nil {
+	events := []models.DomainEvent{orderUploadedEvent(order)}
+	// An order the accrual system has already finished never passes through
+	// the updater, so its final status is announced here; subscribers such as
+	// notifications and webhooks only react to status changes.
+	if status == StatusProcessed || status == string(accrual.StatusInvalid) {
+		events = append(events, newDomainEvent(models.EventOrderStatusChanged, userID, number,
+			models.OrderStatusChangedPayload{
+				Number:         number,
+				PreviousStatus: StatusNew,
+				Status:         status,
+				Accrual:        accrualSum,
+			}))
+	}
+
+	if err := s.repo.SaveOrder(ctx, order, events...); err != nil {
 		return err
 	}
 
 	if status == StatusProcessed && accrualSum != nil && *accrualSum > 0 {
-		if err := s.balanceRepo.IncreaseUserBalance(ctx, userID, *accrualSum); err != nil {
-			logger.Log.Error("failed to increase user balance", zap.Error(err), zap.Int64("userID", userID), zap.Float64("accrual", *accrualSum))
+		credited := newDomainEvent(models.EventBalanceCredited, userID, number,
+			models.BalanceCreditedPayload{OrderNumber: number, Amount: *accrualSum})
+		if err := s.balanceRepo.IncreaseUserBalance(ctx, userID, *accrualSum, credited); err != nil {
+			logger.FromContext(ctx).Error("failed to increase user balance", zap.Error(err), zap.Int64("userID", userID), zap.Float64("accrual", *accrualSum))
 			return err
 		}
-		logger.Log.Info("user balance increased", zap.Int64("userID", userID), zap.Float64("accrual", *accrualSum))
+		metrics.PointsCredited.Add(*accrualSum)
+		logger.FromContext(ctx).Info("user balance increased", zap.Int64("userID", userID), zap.Float64("accrual", *accrualSum))
 	}
 
 	return nil
 }
 
-func (s *orderService) GetUserOrders(ctx context.Context, userID int64) ([]models.Order, error) {
+func (s *orderService) GetUserOrders(ctx context.Context, userID int64) (_ []models.Order, err error) {
+	ctx, span := tracing.Start(ctx, "OrderService.GetUserOrders")
+	defer func() { tracing.End(span, err) }()
+
 	return s.repo.GetOrdersByUser(ctx, userID)
 }
+
+func (s *orderService) ListUserOrders(ctx context.Context, userID int64, filter models.OrderFilter) (_ *models.OrderPage, err error) {
+	ctx, span := tracing.Start(ctx, "OrderService.ListUserOrders")
+	defer func() { tracing.End(span, err) }()
+
+	for _, status := range filter.Statuses {
+		if _, ok := orderStatuses[status]; !ok {
+			return nil, fmt.Errorf("%w: unknown status %q", apperrors.ErrInvalidFilter, status)
+		}
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, fmt.Errorf("%w: from must be before to", apperrors.ErrInvalidFilter)
+	}
+
+	limit, err := pageLimit(filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := decodeCursor(filter.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Limit = limit + 1
+	orders, err := s.repo.GetOrdersPage(ctx, userID, filter, after)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		last := page.Orders[limit-1]
+		page.NextCursor = encodeCursor(models.PageCursor{Time: last.UploadedAt, Key: last.Number})
+	}
+
+	return page, nil
+}
+
+// GetUserOrder reports orders owned by other users as not found so that the
+// endpoint cannot be used to probe which numbers are registered.
+func (s *orderService) GetUserOrder(ctx context.Context, userID int64, number string) (_ *models.OrderDetail, err error) {
+	ctx, span := tracing.Start(ctx, "OrderService.GetUserOrder")
+	defer func() { tracing.End(span, err) }()
+
+	order, err := s.repo.GetOrder(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, apperrors.ErrOrderNotFound
+	}
+
+	history, err := s.repo.GetOrderStatusHistory(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OrderDetail{Order: *order, History: emptyIfNil(history)}, nil
+}
+
+// UploadOrders registers a batch of orders as NEW in one transaction and leaves
+// the accrual lookup to the background updater. Results follow the input
+// order; repeated numbers are reported once.
+func (s *orderService) UploadOrders(ctx context.Context, numbers []string, userID int64) (_ []models.BatchOrderResult, err error) {
+	ctx, span := tracing.Start(ctx, "OrderService.UploadOrders")
+	defer func() { tracing.End(span, err) }()
+
+	if len(numbers) == 0 {
+		return nil, apperrors.ErrEmptyBatch
+	}
+	if len(numbers) > s.batchMaxSize {
+		return nil, fmt.Errorf("%w: at most %d orders per batch", apperrors.ErrBatchTooLarge, s.batchMaxSize)
+	}
+
+	now := time.Now()
+	seen := make(map[string]struct{}, len(numbers))
+	results := make([]models.BatchOrderResult, 0, len(numbers))
+	orders := make([]models.Order, 0, len(numbers))
+
+	for _, number := range numbers {
+		if _, ok := seen[number]; ok {
+			continue
+		}
+		seen[number] = struct{}{}
+
+		if !utils.IsValidLuhn(number) {
+			results = append(results, models.BatchOrderResult{Number: number, Status: models.BatchOrderInvalid})
+			continue
+		}
+
+		results = append(results, models.BatchOrderResult{Number: number, Status: models.BatchOrderAccepted})
+		orders = append(orders, models.Order{
+			Number:     number,
+			Status:     StatusNew,
+			UploadedAt: now,
+			UserID:     userID,
+		})
+	}
+
+	if len(orders) == 0 {
+		return results, nil
+	}
+
+	uploaded := make([]models.DomainEvent, 0, len(orders))
+	for i := range orders {
+		uploaded = append(uploaded, orderUploadedEvent(&orders[i]))
+	}
+
+	existing, err := s.repo.SaveOrders(ctx, orders, uploaded...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range results {
+		ownerID, ok := existing[result.Number]
+		switch {
+		case !ok:
+		case ownerID == userID:
+			results[i].Status = models.BatchOrderAlreadyYours
+		default:
+			results[i].Status = models.BatchOrderConflict
+		}
+	}
+
+	return results, nil
+}
+
+func orderUploadedEvent(order *models.Order) models.DomainEvent {
+	return newDomainEvent(models.EventOrderUploaded, order.UserID, order.Number, models.OrderUploadedPayload{
+		Number:     order.Number,
+		Status:     order.Status,
+		UploadedAt: order.UploadedAt,
+	})
+}
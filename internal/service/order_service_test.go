@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/a2sh3r/gophermart/internal/accrual"
 	"github.com/a2sh3r/gophermart/internal/apperrors"
@@ -9,7 +10,9 @@ import (
 	"github.com/a2sh3r/gophermart/internal/models"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 type fakeAccrualClient struct {
@@ -86,18 +89,18 @@ func TestOrderService_UploadOrder(t *testing.T) {
 				err:        tt.accrualErr,
 				statusCode: tt.accrualStatus,
 			}
-			service := NewOrderService(repo, balanceRepo, client)
+			service := NewOrderService(repo, balanceRepo, client, DefaultOrderBatchSize)
 
 			if !errors.Is(tt.expectedErr, apperrors.ErrInvalidOrderNumber) {
 				repo.EXPECT().GetOrderOwner(ctx, tt.orderNumber).Return(tt.ownerID, tt.ownerErr)
 			}
 
 			if tt.expectedErr == nil || tt.saveOrderErr != nil {
-				repo.EXPECT().SaveOrder(ctx, gomock.Any()).Return(tt.saveOrderErr)
+				repo.EXPECT().SaveOrder(ctx, gomock.Any(), gomock.Any()).Return(tt.saveOrderErr)
 			}
 
 			if tt.accrualResp != nil && tt.accrualResp.Status == accrual.StatusProcessed && tt.accrualResp.Accrual != nil && *tt.accrualResp.Accrual > 0 {
-				balanceRepo.EXPECT().IncreaseUserBalance(ctx, userID, *tt.accrualResp.Accrual).Return(nil)
+				balanceRepo.EXPECT().IncreaseUserBalance(ctx, userID, *tt.accrualResp.Accrual, gomock.Any()).Return(nil)
 			}
 
 			err := service.UploadOrder(ctx, tt.orderNumber, userID)
@@ -111,6 +114,67 @@ func TestOrderService_UploadOrder(t *testing.T) {
 	}
 }
 
+func TestOrderService_UploadOrderFinalStatus(t *testing.T) {
+	accrualSum := 500.0
+
+	tests := []struct {
+		name        string
+		resp        *accrual.AccrualResponse
+		wantChanged bool
+	}{
+		{
+			name:        "заказ уже обработан",
+			resp:        &accrual.AccrualResponse{Status: accrual.StatusProcessed, Accrual: &accrualSum},
+			wantChanged: true,
+		},
+		{
+			name:        "заказ уже отклонён",
+			resp:        &accrual.AccrualResponse{Status: accrual.StatusInvalid},
+			wantChanged: true,
+		},
+		{
+			name: "заказ ещё обрабатывается",
+			resp: &accrual.AccrualResponse{Status: accrual.StatusProcessing},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := context.Background()
+			repo := repoMocks.NewMockOrderRepository(ctrl)
+			balanceRepo := repoMocks.NewMockBalanceRepository(ctrl)
+			service := NewOrderService(repo, balanceRepo, &fakeAccrualClient{resp: tt.resp}, DefaultOrderBatchSize)
+
+			repo.EXPECT().GetOrderOwner(ctx, "79927398713").Return(int64(0), nil)
+			repo.EXPECT().SaveOrder(ctx, gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, order *models.Order, events ...models.DomainEvent) error {
+					if !tt.wantChanged {
+						assert.Len(t, events, 1)
+						return nil
+					}
+					if assert.Len(t, events, 2) {
+						assert.Equal(t, models.EventOrderUploaded, events[0].Type)
+						assert.Equal(t, models.EventOrderStatusChanged, events[1].Type)
+
+						var payload models.OrderStatusChangedPayload
+						require.NoError(t, json.Unmarshal(events[1].Payload, &payload))
+						assert.Equal(t, StatusNew, payload.PreviousStatus)
+						assert.Equal(t, string(tt.resp.Status), payload.Status)
+					}
+					return nil
+				})
+			if tt.resp.Status == accrual.StatusProcessed {
+				balanceRepo.EXPECT().IncreaseUserBalance(ctx, int64(1), accrualSum, gomock.Any()).Return(nil)
+			}
+
+			assert.NoError(t, service.UploadOrder(ctx, "79927398713", 1))
+		})
+	}
+}
+
 func TestOrderService_GetUserOrders(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -118,7 +182,7 @@ func TestOrderService_GetUserOrders(t *testing.T) {
 	repo := repoMocks.NewMockOrderRepository(ctrl)
 	balanceRepo := repoMocks.NewMockBalanceRepository(ctrl)
 	client := &fakeAccrualClient{}
-	service := NewOrderService(repo, balanceRepo, client)
+	service := NewOrderService(repo, balanceRepo, client, DefaultOrderBatchSize)
 	ctx := context.Background()
 	userID := int64(1)
 
@@ -129,3 +193,233 @@ func TestOrderService_GetUserOrders(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expectedOrders, orders)
 }
+
+func TestOrderService_ListUserOrders(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	orders := []models.Order{
+		{Number: "3", UploadedAt: now},
+		{Number: "2", UploadedAt: now.Add(-time.Minute)},
+		{Number: "1", UploadedAt: now.Add(-2 * time.Minute)},
+	}
+
+	tests := []struct {
+		name        string
+		filter      models.OrderFilter
+		mockSetup   func(m *repoMocks.MockOrderRepository)
+		wantOrders  int
+		wantNext    bool
+		expectedErr error
+	}{
+		{
+			name:   "есть следующая страница",
+			filter: models.OrderFilter{Limit: 2},
+			mockSetup: func(m *repoMocks.MockOrderRepository) {
+				m.EXPECT().GetOrdersPage(gomock.Any(), int64(1), models.OrderFilter{Limit: 3}, (*models.PageCursor)(nil)).Return(orders, nil)
+			},
+			wantOrders: 2,
+			wantNext:   true,
+		},
+		{
+			name:   "последняя страница",
+			filter: models.OrderFilter{Limit: 5, Statuses: []string{"NEW"}},
+			mockSetup: func(m *repoMocks.MockOrderRepository) {
+				m.EXPECT().GetOrdersPage(gomock.Any(), int64(1), gomock.Any(), gomock.Any()).Return(orders, nil)
+			},
+			wantOrders: 3,
+		},
+		{
+			name:   "лимит по умолчанию",
+			filter: models.OrderFilter{Statuses: []string{"PROCESSED"}},
+			mockSetup: func(m *repoMocks.MockOrderRepository) {
+				m.EXPECT().GetOrdersPage(gomock.Any(), int64(1), models.OrderFilter{Statuses: []string{"PROCESSED"}, Limit: DefaultPageLimit + 1}, gomock.Any()).Return(nil, nil)
+			},
+		},
+		{
+			name:        "неизвестный статус",
+			filter:      models.OrderFilter{Statuses: []string{"DONE"}},
+			mockSetup:   func(m *repoMocks.MockOrderRepository) {},
+			expectedErr: apperrors.ErrInvalidFilter,
+		},
+		{
+			name:        "слишком большой лимит",
+			filter:      models.OrderFilter{Limit: MaxPageLimit + 1},
+			mockSetup:   func(m *repoMocks.MockOrderRepository) {},
+			expectedErr: apperrors.ErrInvalidPageLimit,
+		},
+		{
+			name:        "некорректный курсор",
+			filter:      models.OrderFilter{Cursor: "!!!"},
+			mockSetup:   func(m *repoMocks.MockOrderRepository) {},
+			expectedErr: apperrors.ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repoMocks.NewMockOrderRepository(ctrl)
+			tt.mockSetup(repo)
+			service := NewOrderService(repo, repoMocks.NewMockBalanceRepository(ctrl), &fakeAccrualClient{}, DefaultOrderBatchSize)
+
+			page, err := service.ListUserOrders(context.Background(), 1, tt.filter)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, page.Orders, tt.wantOrders)
+			assert.Equal(t, tt.wantNext, page.NextCursor != "")
+		})
+	}
+}
+
+func TestOrderService_ListUserOrdersCursor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uploadedAt := time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC)
+	repo := repoMocks.NewMockOrderRepository(ctrl)
+	repo.EXPECT().GetOrdersPage(gomock.Any(), int64(1), gomock.Any(), (*models.PageCursor)(nil)).
+		Return([]models.Order{{Number: "2", UploadedAt: uploadedAt}, {Number: "1"}}, nil)
+	repo.EXPECT().GetOrdersPage(gomock.Any(), int64(1), gomock.Any(), &models.PageCursor{Time: uploadedAt, Key: "2"}).
+		Return(nil, nil)
+
+	service := NewOrderService(repo, repoMocks.NewMockBalanceRepository(ctrl), &fakeAccrualClient{}, DefaultOrderBatchSize)
+
+	page, err := service.ListUserOrders(context.Background(), 1, models.OrderFilter{Limit: 1})
+	assert.NoError(t, err)
+
+	_, err = service.ListUserOrders(context.Background(), 1, models.OrderFilter{Limit: 1, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+}
+
+func TestOrderService_GetUserOrder(t *testing.T) {
+	accrualSum := 100.0
+	history := []models.OrderStatusChange{
+		{Status: "NEW"},
+		{Status: "PROCESSED", Accrual: &accrualSum},
+	}
+
+	tests := []struct {
+		name        string
+		mockSetup   func(m *repoMocks.MockOrderRepository)
+		wantHistory int
+		expectedErr error
+	}{
+		{
+			name: "заказ с историей",
+			mockSetup: func(m *repoMocks.MockOrderRepository) {
+				m.EXPECT().GetOrder(gomock.Any(), "12345678903").Return(&models.Order{Number: "12345678903", Status: "PROCESSED", UserID: 1}, nil)
+				m.EXPECT().GetOrderStatusHistory(gomock.Any(), "12345678903").Return(history, nil)
+			},
+			wantHistory: 2,
+		},
+		{
+			name: "заказ другого пользователя",
+			mockSetup: func(m *repoMocks.MockOrderRepository) {
+				m.EXPECT().GetOrder(gomock.Any(), "12345678903").Return(&models.Order{Number: "12345678903", UserID: 2}, nil)
+			},
+			expectedErr: apperrors.ErrOrderNotFound,
+		},
+		{
+			name: "заказ не найден",
+			mockSetup: func(m *repoMocks.MockOrderRepository) {
+				m.EXPECT().GetOrder(gomock.Any(), "12345678903").Return(nil, apperrors.ErrOrderNotFound)
+			},
+			expectedErr: apperrors.ErrOrderNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repoMocks.NewMockOrderRepository(ctrl)
+			tt.mockSetup(repo)
+			service := NewOrderService(repo, repoMocks.NewMockBalanceRepository(ctrl), &fakeAccrualClient{}, DefaultOrderBatchSize)
+
+			detail, err := service.GetUserOrder(context.Background(), 1, "12345678903")
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, detail.History, tt.wantHistory)
+		})
+	}
+}
+
+func TestOrderService_UploadOrders(t *testing.T) {
+	tests := []struct {
+		name        string
+		numbers     []string
+		mockSetup   func(m *repoMocks.MockOrderRepository)
+		want        []models.BatchOrderResult
+		expectedErr error
+	}{
+		{
+			name:    "смешанный пакет",
+			numbers: []string{"12345678903", "79927398713", "4561261212345467", "123", "12345678903"},
+			mockSetup: func(m *repoMocks.MockOrderRepository) {
+				m.EXPECT().SaveOrders(gomock.Any(), gomock.Len(3), gomock.Len(3)).Return(map[string]int64{
+					"79927398713":      1,
+					"4561261212345467": 2,
+				}, nil)
+			},
+			want: []models.BatchOrderResult{
+				{Number: "12345678903", Status: models.BatchOrderAccepted},
+				{Number: "79927398713", Status: models.BatchOrderAlreadyYours},
+				{Number: "4561261212345467", Status: models.BatchOrderConflict},
+				{Number: "123", Status: models.BatchOrderInvalid},
+			},
+		},
+		{
+			name:      "только некорректные номера",
+			numbers:   []string{"abc"},
+			mockSetup: func(m *repoMocks.MockOrderRepository) {},
+			want:      []models.BatchOrderResult{{Number: "abc", Status: models.BatchOrderInvalid}},
+		},
+		{
+			name:        "пустой пакет",
+			mockSetup:   func(m *repoMocks.MockOrderRepository) {},
+			expectedErr: apperrors.ErrEmptyBatch,
+		},
+		{
+			name:        "слишком большой пакет",
+			numbers:     []string{"1", "2", "3", "4", "5", "6"},
+			mockSetup:   func(m *repoMocks.MockOrderRepository) {},
+			expectedErr: apperrors.ErrBatchTooLarge,
+		},
+		{
+			name:    "ошибка базы данных",
+			numbers: []string{"12345678903"},
+			mockSetup: func(m *repoMocks.MockOrderRepository) {
+				m.EXPECT().SaveOrders(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			expectedErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repoMocks.NewMockOrderRepository(ctrl)
+			tt.mockSetup(repo)
+			service := NewOrderService(repo, repoMocks.NewMockBalanceRepository(ctrl), &fakeAccrualClient{}, 5)
+
+			results, err := service.UploadOrders(context.Background(), tt.numbers, 1)
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, results)
+		})
+	}
+}
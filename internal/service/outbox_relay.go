@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/a2sh3r/gophermart/internal/events"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/repository"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	outboxBatchSize   = 100
+	outboxLease       = time.Minute
+	outboxMaxAttempts = 10
+	outboxBaseBackoff = 5 * time.Second
+	outboxMaxBackoff  = 10 * time.Minute
+)
+
+// OutboxRelay drains the outbox into the in-process event bus. An event is
+// marked processed once every subscriber has handled it; if any subscriber
+// fails the whole event is retried later, so all subscribers must be
+// idempotent. Events that keep failing are parked as failed.
+type OutboxRelay struct {
+	repo         repository.OutboxRepository
+	bus          *events.Bus
+	pollInterval time.Duration
+	now          func() time.Time
+}
+
+func NewOutboxRelay(repo repository.OutboxRepository, bus *events.Bus, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		repo:         repo,
+		bus:          bus,
+		pollInterval: interval,
+		now:          time.Now,
+	}
+}
+
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.relayPending(ctx)
+		}
+	}
+}
+
+func (r *OutboxRelay) RunCleanup(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := r.repo.DeleteProcessedEventsBefore(ctx, r.now().Add(-retention))
+			if err != nil {
+				logger.FromContext(ctx).Error("failed to clean up outbox events", zap.Error(err))
+				continue
+			}
+			if deleted > 0 {
+				logger.FromContext(ctx).Info("outbox events cleaned up", zap.Int64("deleted", deleted))
+			}
+		}
+	}
+}
+
+func (r *OutboxRelay) relayPending(ctx context.Context) {
+	pending, err := r.repo.ClaimPendingEvents(ctx, outboxBatchSize, outboxLease)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to claim outbox events", zap.Error(err))
+		return
+	}
+
+	for _, event := range pending {
+		if ctx.Err() != nil {
+			return
+		}
+		r.relay(ctx, event)
+	}
+}
+
+func (r *OutboxRelay) relay(ctx context.Context, event models.DomainEvent) {
+	dispatchErr := r.bus.Dispatch(ctx, event)
+
+	var err error
+	attempt := event.Attempts + 1
+	switch {
+	case dispatchErr == nil:
+		err = r.repo.MarkEventProcessed(ctx, event.ID)
+	case attempt >= outboxMaxAttempts:
+		logger.FromContext(ctx).Error("domain event exhausted retries",
+			zap.Int64("event", event.ID), zap.String("type", event.Type), zap.Error(dispatchErr))
+		err = r.repo.MarkEventFailed(ctx, event.ID, dispatchErr.Error())
+	default:
+		logger.FromContext(ctx).Warn("domain event subscriber failed",
+			zap.Int64("event", event.ID), zap.String("type", event.Type), zap.Error(dispatchErr))
+		err = r.repo.RescheduleEvent(ctx, event.ID, dispatchErr.Error(),
+			r.now().Add(exponentialBackoff(attempt, outboxBaseBackoff, outboxMaxBackoff)))
+	}
+
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to record outbox event result", zap.Int64("event", event.ID), zap.Error(err))
+	}
+}
+
+// newDomainEvent builds an outbox event; payload is one of the models.*Payload
+// structs, which always marshal.
+func newDomainEvent(eventType string, userID int64, aggregateID string, payload interface{}) models.DomainEvent {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		logger.Log.Error("failed to marshal domain event payload", zap.String("type", eventType), zap.Error(err))
+	}
+	return models.DomainEvent{
+		Type:        eventType,
+		UserID:      userID,
+		AggregateID: aggregateID,
+		Payload:     data,
+		OccurredAt:  time.Now(),
+	}
+}
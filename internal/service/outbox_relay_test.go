@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/a2sh3r/gophermart/internal/events"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/mocks/repository_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestOutboxRelay_relayPending(t *testing.T) {
+	logger.Log = zap.NewNop()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		attempts   int
+		handlerErr error
+		expect     func(m *repository_mocks.MockOutboxRepository)
+	}{
+		{
+			name: "событие обработано",
+			expect: func(m *repository_mocks.MockOutboxRepository) {
+				m.EXPECT().MarkEventProcessed(gomock.Any(), int64(3)).Return(nil)
+			},
+		},
+		{
+			name:       "ошибка подписчика — повтор с back-off",
+			attempts:   1,
+			handlerErr: errors.New("boom"),
+			expect: func(m *repository_mocks.MockOutboxRepository) {
+				m.EXPECT().RescheduleEvent(gomock.Any(), int64(3), "webhooks: boom", now.Add(10*time.Second)).Return(nil)
+			},
+		},
+		{
+			name:       "исчерпаны попытки",
+			attempts:   outboxMaxAttempts - 1,
+			handlerErr: errors.New("boom"),
+			expect: func(m *repository_mocks.MockOutboxRepository) {
+				m.EXPECT().MarkEventFailed(gomock.Any(), int64(3), "webhooks: boom").Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			event := models.DomainEvent{ID: 3, Type: models.EventWithdrawalMade, UserID: 1, Attempts: tt.attempts}
+
+			repo := repository_mocks.NewMockOutboxRepository(ctrl)
+			repo.EXPECT().ClaimPendingEvents(gomock.Any(), outboxBatchSize, outboxLease).Return([]models.DomainEvent{event}, nil)
+			tt.expect(repo)
+
+			var received []models.DomainEvent
+			bus := events.NewBus()
+			bus.Subscribe("webhooks", func(_ context.Context, e models.DomainEvent) error {
+				received = append(received, e)
+				return tt.handlerErr
+			}, models.EventWithdrawalMade)
+
+			relay := NewOutboxRelay(repo, bus, time.Second)
+			relay.now = func() time.Time { return now }
+			relay.relayPending(context.Background())
+
+			assert.Equal(t, []models.DomainEvent{event}, received)
+		})
+	}
+}
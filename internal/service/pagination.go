@@ -0,0 +1,54 @@
+package service
+
+import (
+	"encoding/base64"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+func encodeCursor(c models.PageCursor) string {
+	raw := strconv.FormatInt(c.Time.UnixNano(), 10) + ":" + c.Key
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (*models.PageCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, apperrors.ErrInvalidCursor
+	}
+
+	nanos, key, ok := strings.Cut(string(raw), ":")
+	if !ok || key == "" {
+		return nil, apperrors.ErrInvalidCursor
+	}
+
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, apperrors.ErrInvalidCursor
+	}
+
+	return &models.PageCursor{Time: time.Unix(0, n).UTC(), Key: key}, nil
+}
+
+func pageLimit(limit int) (int, error) {
+	switch {
+	case limit == 0:
+		return DefaultPageLimit, nil
+	case limit < 0 || limit > MaxPageLimit:
+		return 0, apperrors.ErrInvalidPageLimit
+	default:
+		return limit, nil
+	}
+}
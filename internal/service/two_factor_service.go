@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/repository"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"strings"
+	"time"
+)
+
+const (
+	totpPeriod        = 30
+	totpSkew          = 1
+	recoveryCodeCount = 10
+
+	// totpMaxFailures invalid codes in a row lock verification for
+	// totpLockout, which keeps brute-forcing a code out of reach even for
+	// someone who knows the password.
+	totpMaxFailures = 5
+	totpLockout     = 15 * time.Minute
+)
+
+type TwoFactorService interface {
+	Setup(ctx context.Context, userID int64) (*models.TOTPSetup, error)
+	Enable(ctx context.Context, userID int64, code string) ([]string, error)
+	Disable(ctx context.Context, userID int64, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error)
+	Verify(ctx context.Context, userID int64, code string) error
+	VerifyWithdrawal(ctx context.Context, userID int64, code string) error
+}
+
+type twoFactorService struct {
+	repo                 repository.TwoFactorRepository
+	userRepo             repository.UserRepository
+	issuer               string
+	requireForWithdrawal bool
+	now                  func() time.Time
+}
+
+func NewTwoFactorService(repo repository.TwoFactorRepository, userRepo repository.UserRepository, issuer string, requireForWithdrawal bool) TwoFactorService {
+	return &twoFactorService{
+		repo:                 repo,
+		userRepo:             userRepo,
+		issuer:               issuer,
+		requireForWithdrawal: requireForWithdrawal,
+		now:                  time.Now,
+	}
+}
+
+func (s *twoFactorService) Setup(ctx context.Context, userID int64) (*models.TOTPSetup, error) {
+	existing, err := s.repo.GetTOTP(ctx, userID)
+	if err != nil && !errors.Is(err, apperrors.ErrTOTPNotConfigured) {
+		return nil, err
+	}
+	if existing != nil && existing.Enabled {
+		return nil, apperrors.ErrTOTPAlreadyEnabled
+	}
+
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      s.issuer,
+		AccountName: user.Login,
+		Period:      totpPeriod,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SaveTOTPSecret(ctx, userID, key.Secret()); err != nil {
+		return nil, err
+	}
+
+	return &models.TOTPSetup{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+	}, nil
+}
+
+func (s *twoFactorService) Enable(ctx context.Context, userID int64, code string) ([]string, error) {
+	state, err := s.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if state.Enabled {
+		return nil, apperrors.ErrTOTPAlreadyEnabled
+	}
+
+	if err := s.verifyTOTP(ctx, state, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.EnableTOTP(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *twoFactorService) Disable(ctx context.Context, userID int64, code string) error {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+
+	return s.repo.DisableTOTP(ctx, userID)
+}
+
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	state, err := s.enabledTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyTOTP(ctx, state, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *twoFactorService) Verify(ctx context.Context, userID int64, code string) error {
+	state, err := s.enabledTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	code = normalizeCode(code)
+	if code == "" {
+		return apperrors.ErrTOTPRequired
+	}
+
+	return s.limitAttempts(ctx, state, func() error {
+		if len(code) == int(otp.DigitsSix) {
+			return s.matchTOTP(ctx, state, code)
+		}
+
+		used, err := s.repo.UseRecoveryCode(ctx, userID, hashRecoveryCode(code))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return apperrors.ErrInvalidTOTPCode
+		}
+		return nil
+	})
+}
+
+func (s *twoFactorService) VerifyWithdrawal(ctx context.Context, userID int64, code string) error {
+	if !s.requireForWithdrawal {
+		return nil
+	}
+
+	state, err := s.repo.GetTOTP(ctx, userID)
+	if errors.Is(err, apperrors.ErrTOTPNotConfigured) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !state.Enabled {
+		return nil
+	}
+
+	if normalizeCode(code) == "" {
+		return apperrors.ErrTOTPRequired
+	}
+
+	return s.verifyTOTP(ctx, state, code)
+}
+
+func (s *twoFactorService) enabledTOTP(ctx context.Context, userID int64) (*models.TOTP, error) {
+	state, err := s.repo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Enabled {
+		return nil, apperrors.ErrTOTPNotConfigured
+	}
+	return state, nil
+}
+
+// verifyTOTP checks a TOTP code under the failed attempts limit.
+func (s *twoFactorService) verifyTOTP(ctx context.Context, state *models.TOTP, code string) error {
+	return s.limitAttempts(ctx, state, func() error {
+		return s.matchTOTP(ctx, state, code)
+	})
+}
+
+// limitAttempts reserves an attempt before checking a code and clears the
+// count after a valid one. The reservation is what enforces the lockout: the
+// state loaded earlier may be stale when concurrent requests race.
+func (s *twoFactorService) limitAttempts(ctx context.Context, state *models.TOTP, check func() error) error {
+	now := s.now()
+	reserved, err := s.repo.ReserveTOTPAttempt(ctx, state.UserID, totpMaxFailures, now, now.Add(totpLockout))
+	if err != nil {
+		return err
+	}
+	if !reserved {
+		return apperrors.ErrTOTPLocked
+	}
+
+	if err := check(); err != nil {
+		return err
+	}
+	return s.repo.ResetTOTPFailures(ctx, state.UserID)
+}
+
+// matchTOTP accepts a code from the current or an adjacent time step and
+// records the matched step so the same code cannot be replayed.
+func (s *twoFactorService) matchTOTP(ctx context.Context, state *models.TOTP, code string) error {
+	code = normalizeCode(code)
+	now := s.now()
+
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		t := now.Add(time.Duration(skew*totpPeriod) * time.Second)
+		expected, err := totp.GenerateCodeCustom(state.Secret, t, totp.ValidateOpts{
+			Period:    totpPeriod,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return err
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) != 1 {
+			continue
+		}
+
+		fresh, err := s.repo.UseTOTPStep(ctx, state.UserID, t.Unix()/totpPeriod)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return apperrors.ErrInvalidTOTPCode
+		}
+		return nil
+	}
+
+	return apperrors.ErrInvalidTOTPCode
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		buf := make([]byte, 10)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))
+		code := raw[:8] + "-" + raw[8:16]
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(normalizeCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(strings.ReplaceAll(code, "-", ""), " ", "")
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/mocks/repository_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/pquerna/otp/totp"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+func newTestTwoFactorService(repo *repository_mocks.MockTwoFactorRepository, userRepo *repository_mocks.MockUserRepository, requireForWithdrawal bool, now time.Time) *twoFactorService {
+	s := NewTwoFactorService(repo, userRepo, "Gophermart", requireForWithdrawal).(*twoFactorService)
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestTwoFactorService_Setup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository_mocks.NewMockTwoFactorRepository(ctrl)
+	userRepo := repository_mocks.NewMockUserRepository(ctrl)
+
+	repo.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(nil, apperrors.ErrTOTPNotConfigured)
+	userRepo.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(&models.User{ID: 1, Login: "user1"}, nil)
+	repo.EXPECT().SaveTOTPSecret(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+
+	s := newTestTwoFactorService(repo, userRepo, false, time.Now())
+
+	setup, err := s.Setup(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if setup.Secret == "" {
+		t.Error("expected secret to be generated")
+	}
+	if setup.URI == "" {
+		t.Error("expected otpauth URI to be generated")
+	}
+}
+
+func TestTwoFactorService_SetupAlreadyEnabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository_mocks.NewMockTwoFactorRepository(ctrl)
+	userRepo := repository_mocks.NewMockUserRepository(ctrl)
+
+	repo.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(&models.TOTP{UserID: 1, Enabled: true}, nil)
+
+	s := newTestTwoFactorService(repo, userRepo, false, time.Now())
+
+	if _, err := s.Setup(context.Background(), 1); !errors.Is(err, apperrors.ErrTOTPAlreadyEnabled) {
+		t.Errorf("expected ErrTOTPAlreadyEnabled, got %v", err)
+	}
+}
+
+// expectTOTPAttempt expects a reserved code check; a valid code then clears
+// the failure count.
+func expectTOTPAttempt(m *repository_mocks.MockTwoFactorRepository, now time.Time, valid bool) {
+	m.EXPECT().ReserveTOTPAttempt(gomock.Any(), int64(1), totpMaxFailures, now, now.Add(totpLockout)).Return(true, nil)
+	if valid {
+		m.EXPECT().ResetTOTPFailures(gomock.Any(), int64(1)).Return(nil)
+	}
+}
+
+func TestTwoFactorService_Enable(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	validCode, _ := totp.GenerateCode(testTOTPSecret, now)
+
+	tests := []struct {
+		name        string
+		code        string
+		mockSetup   func(m *repository_mocks.MockTwoFactorRepository)
+		expectedErr error
+	}{
+		{
+			name: "успешное включение",
+			code: validCode,
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(&models.TOTP{UserID: 1, Secret: testTOTPSecret}, nil)
+				expectTOTPAttempt(m, now, true)
+				m.EXPECT().UseTOTPStep(gomock.Any(), int64(1), now.Unix()/30).Return(true, nil)
+				m.EXPECT().EnableTOTP(gomock.Any(), int64(1), gomock.Len(recoveryCodeCount)).Return(nil)
+			},
+		},
+		{
+			name: "неверный код",
+			code: "000000",
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(&models.TOTP{UserID: 1, Secret: testTOTPSecret}, nil)
+				expectTOTPAttempt(m, now, false)
+			},
+			expectedErr: apperrors.ErrInvalidTOTPCode,
+		},
+		{
+			name: "настройка не начата",
+			code: validCode,
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(nil, apperrors.ErrTOTPNotConfigured)
+			},
+			expectedErr: apperrors.ErrTOTPNotConfigured,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repository_mocks.NewMockTwoFactorRepository(ctrl)
+			tt.mockSetup(repo)
+
+			s := newTestTwoFactorService(repo, repository_mocks.NewMockUserRepository(ctrl), false, now)
+			codes, err := s.Enable(context.Background(), 1, tt.code)
+
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(codes) != recoveryCodeCount {
+				t.Errorf("expected %d recovery codes, got %d", recoveryCodeCount, len(codes))
+			}
+		})
+	}
+}
+
+func TestTwoFactorService_Verify(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	validCode, _ := totp.GenerateCode(testTOTPSecret, now)
+	previousCode, _ := totp.GenerateCode(testTOTPSecret, now.Add(-30*time.Second))
+	enabled := &models.TOTP{UserID: 1, Secret: testTOTPSecret, Enabled: true}
+
+	tests := []struct {
+		name        string
+		code        string
+		mockSetup   func(m *repository_mocks.MockTwoFactorRepository)
+		expectedErr error
+	}{
+		{
+			name: "текущий код",
+			code: validCode,
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(enabled, nil)
+				expectTOTPAttempt(m, now, true)
+				m.EXPECT().UseTOTPStep(gomock.Any(), int64(1), now.Unix()/30).Return(true, nil)
+			},
+		},
+		{
+			name: "код из предыдущего интервала",
+			code: previousCode,
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(enabled, nil)
+				expectTOTPAttempt(m, now, true)
+				m.EXPECT().UseTOTPStep(gomock.Any(), int64(1), now.Unix()/30-1).Return(true, nil)
+			},
+		},
+		{
+			name: "повторное использование кода",
+			code: validCode,
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(enabled, nil)
+				m.EXPECT().UseTOTPStep(gomock.Any(), int64(1), now.Unix()/30).Return(false, nil)
+				expectTOTPAttempt(m, now, false)
+			},
+			expectedErr: apperrors.ErrInvalidTOTPCode,
+		},
+		{
+			name: "код восстановления",
+			code: "abcdefgh-ijklmnop",
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(enabled, nil)
+				expectTOTPAttempt(m, now, true)
+				m.EXPECT().UseRecoveryCode(gomock.Any(), int64(1), hashRecoveryCode("abcdefghijklmnop")).Return(true, nil)
+			},
+		},
+		{
+			name: "использованный код восстановления",
+			code: "abcdefgh-ijklmnop",
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(enabled, nil)
+				m.EXPECT().UseRecoveryCode(gomock.Any(), int64(1), gomock.Any()).Return(false, nil)
+				expectTOTPAttempt(m, now, false)
+			},
+			expectedErr: apperrors.ErrInvalidTOTPCode,
+		},
+		{
+			name: "блокировка установлена параллельным запросом",
+			code: validCode,
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(enabled, nil)
+				m.EXPECT().ReserveTOTPAttempt(gomock.Any(), int64(1), totpMaxFailures, now, now.Add(totpLockout)).Return(false, nil)
+			},
+			expectedErr: apperrors.ErrTOTPLocked,
+		},
+		{
+			name: "2FA не включена",
+			code: validCode,
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(&models.TOTP{UserID: 1, Secret: testTOTPSecret}, nil)
+			},
+			expectedErr: apperrors.ErrTOTPNotConfigured,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repository_mocks.NewMockTwoFactorRepository(ctrl)
+			tt.mockSetup(repo)
+
+			s := newTestTwoFactorService(repo, repository_mocks.NewMockUserRepository(ctrl), false, now)
+			err := s.Verify(context.Background(), 1, tt.code)
+
+			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr == nil && err != nil {
+				t.Errorf("expected nil error, got %v", err)
+			}
+		})
+	}
+}
+
+// The snapshot returned by GetTOTP never shows the lock, as when concurrent
+// requests all load the state before any of them records a failure.
+func TestTwoFactorService_VerifyLockoutStaleState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	validCode, _ := totp.GenerateCode(testTOTPSecret, now)
+	snapshot := models.TOTP{UserID: 1, Secret: testTOTPSecret, Enabled: true}
+
+	var (
+		failedAttempts int
+		lockedUntil    *time.Time
+	)
+	repo := repository_mocks.NewMockTwoFactorRepository(ctrl)
+	repo.EXPECT().GetTOTP(gomock.Any(), int64(1)).DoAndReturn(func(context.Context, int64) (*models.TOTP, error) {
+		current := snapshot
+		return &current, nil
+	}).AnyTimes()
+	repo.EXPECT().ReserveTOTPAttempt(gomock.Any(), int64(1), totpMaxFailures, now, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, maxFailures int, now, lockUntil time.Time) (bool, error) {
+			if lockedUntil != nil && now.Before(*lockedUntil) {
+				return false, nil
+			}
+			failedAttempts++
+			lockedUntil = nil
+			if failedAttempts >= maxFailures {
+				failedAttempts = 0
+				lockedUntil = &lockUntil
+			}
+			return true, nil
+		}).AnyTimes()
+
+	s := newTestTwoFactorService(repo, repository_mocks.NewMockUserRepository(ctrl), false, now)
+
+	var checked, locked int
+	for i := 0; i < 2*totpMaxFailures; i++ {
+		err := s.Verify(context.Background(), 1, "000000")
+		switch {
+		case errors.Is(err, apperrors.ErrInvalidTOTPCode):
+			checked++
+		case errors.Is(err, apperrors.ErrTOTPLocked):
+			locked++
+		default:
+			t.Fatalf("attempt %d: unexpected error %v", i+1, err)
+		}
+	}
+	if checked != totpMaxFailures || locked != totpMaxFailures {
+		t.Errorf("got %d checked and %d locked attempts, want %d of each", checked, locked, totpMaxFailures)
+	}
+
+	if err := s.Verify(context.Background(), 1, validCode); !errors.Is(err, apperrors.ErrTOTPLocked) {
+		t.Errorf("expected %v for a valid code while locked, got %v", apperrors.ErrTOTPLocked, err)
+	}
+	if lockedUntil == nil || !lockedUntil.Equal(now.Add(totpLockout)) {
+		t.Errorf("expected lock until %v, got %v", now.Add(totpLockout), lockedUntil)
+	}
+}
+
+func TestTwoFactorService_VerifyWithdrawal(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	validCode, _ := totp.GenerateCode(testTOTPSecret, now)
+	enabled := &models.TOTP{UserID: 1, Secret: testTOTPSecret, Enabled: true}
+
+	tests := []struct {
+		name        string
+		require     bool
+		code        string
+		mockSetup   func(m *repository_mocks.MockTwoFactorRepository)
+		expectedErr error
+	}{
+		{
+			name:      "проверка отключена",
+			require:   false,
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {},
+		},
+		{
+			name:    "2FA не настроена",
+			require: true,
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(nil, apperrors.ErrTOTPNotConfigured)
+			},
+		},
+		{
+			name:    "код не передан",
+			require: true,
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(enabled, nil)
+			},
+			expectedErr: apperrors.ErrTOTPRequired,
+		},
+		{
+			name:    "код восстановления не принимается",
+			require: true,
+			code:    "abcdefgh-ijklmnop",
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(enabled, nil)
+				expectTOTPAttempt(m, now, false)
+			},
+			expectedErr: apperrors.ErrInvalidTOTPCode,
+		},
+		{
+			name:    "верный код",
+			require: true,
+			code:    validCode,
+			mockSetup: func(m *repository_mocks.MockTwoFactorRepository) {
+				m.EXPECT().GetTOTP(gomock.Any(), int64(1)).Return(enabled, nil)
+				expectTOTPAttempt(m, now, true)
+				m.EXPECT().UseTOTPStep(gomock.Any(), int64(1), now.Unix()/30).Return(true, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repository_mocks.NewMockTwoFactorRepository(ctrl)
+			tt.mockSetup(repo)
+
+			s := newTestTwoFactorService(repo, repository_mocks.NewMockUserRepository(ctrl), tt.require, now)
+			err := s.VerifyWithdrawal(context.Background(), 1, tt.code)
+
+			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr == nil && err != nil {
+				t.Errorf("expected nil error, got %v", err)
+			}
+		})
+	}
+}
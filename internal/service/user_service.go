@@ -4,35 +4,51 @@ import (
 	"context"
 	"errors"
 	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/password"
+	"go.uber.org/zap"
 
 	"github.com/a2sh3r/gophermart/internal/models"
 	"github.com/a2sh3r/gophermart/internal/repository"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService interface {
 	Register(ctx context.Context, login, password string) error
 	Authenticate(ctx context.Context, login, password string) error
 	GetUserByLogin(ctx context.Context, login string) (*models.User, error)
+	GetUserByID(ctx context.Context, userID int64) (*models.User, error)
+	ChangePassword(ctx context.Context, userID int64, currentPassword, newPassword string) error
+	GetTokenVersion(ctx context.Context, userID int64) (int64, error)
+	GetUserRole(ctx context.Context, userID int64) (string, error)
 }
 
 type userService struct {
-	repo repository.UserRepository
+	repo   repository.UserRepository
+	policy password.Policy
+	hasher password.Hasher
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
-	return &userService{repo: repo}
+func NewUserService(repo repository.UserRepository, policy password.Policy, hasher password.Hasher) UserService {
+	return &userService{
+		repo:   repo,
+		policy: policy,
+		hasher: hasher,
+	}
 }
 
 func (s *userService) Register(ctx context.Context, login, password string) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err := s.policy.Validate(login, password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(password)
 	if err != nil {
 		return err
 	}
 
 	user := &models.User{
 		Login:    login,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 	}
 
 	err = s.repo.CreateUser(ctx, user)
@@ -48,14 +64,85 @@ func (s *userService) Authenticate(ctx context.Context, login, password string)
 		return apperrors.ErrInvalidCredentials
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
-	if err != nil {
+	ok, err := s.hasher.Verify(password, user.Password)
+	if err != nil || !ok {
 		return apperrors.ErrInvalidCredentials
 	}
 
+	if s.hasher.NeedsRehash(user.Password) {
+		s.rehash(ctx, user.ID, user.Password, password)
+	}
+
 	return nil
 }
 
+func (s *userService) rehash(ctx context.Context, userID int64, verifiedHash, password string) {
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to rehash password", zap.Int64("userID", userID), zap.Error(err))
+		return
+	}
+
+	updated, err := s.repo.UpdatePasswordHash(ctx, userID, verifiedHash, hashedPassword)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to save rehashed password", zap.Int64("userID", userID), zap.Error(err))
+		return
+	}
+	if !updated {
+		logger.FromContext(ctx).Info("password changed during rehash, keeping the new one", zap.Int64("userID", userID))
+		return
+	}
+
+	logger.FromContext(ctx).Info("password rehashed", zap.Int64("userID", userID))
+}
+
 func (s *userService) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
 	return s.repo.GetUserByLogin(ctx, login)
 }
+
+func (s *userService) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
+	return s.repo.GetUserByID(ctx, userID)
+}
+
+func (s *userService) ChangePassword(ctx context.Context, userID int64, currentPassword, newPassword string) error {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.hasher.Verify(currentPassword, user.Password)
+	if err != nil || !ok {
+		return apperrors.ErrInvalidCredentials
+	}
+
+	if currentPassword == newPassword {
+		return apperrors.ErrSamePassword
+	}
+
+	if err := s.policy.Validate(user.Login, newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.UpdatePassword(ctx, userID, hashedPassword)
+}
+
+func (s *userService) GetTokenVersion(ctx context.Context, userID int64) (int64, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return user.TokenVersion, nil
+}
+
+func (s *userService) GetUserRole(ctx context.Context, userID int64) (string, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return user.Role, nil
+}
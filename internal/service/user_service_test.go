@@ -8,6 +8,7 @@ import (
 	"github.com/a2sh3r/gophermart/internal/apperrors"
 	"github.com/a2sh3r/gophermart/internal/mocks/repository_mocks"
 	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/password"
 	"github.com/golang/mock/gomock"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -46,6 +47,20 @@ func TestUserService_Register(t *testing.T) {
 			},
 			expectedErr: errors.New("db fail"),
 		},
+		{
+			name:        "слишком короткий пароль",
+			login:       "user4",
+			password:    "short",
+			mockSetup:   func(m *repository_mocks.MockUserRepository) {},
+			expectedErr: apperrors.ErrWeakPassword,
+		},
+		{
+			name:        "пароль совпадает с логином",
+			login:       "longlogin",
+			password:    "longlogin",
+			mockSetup:   func(m *repository_mocks.MockUserRepository) {},
+			expectedErr: apperrors.ErrWeakPassword,
+		},
 	}
 
 	for _, tt := range tests {
@@ -56,10 +71,10 @@ func TestUserService_Register(t *testing.T) {
 			repo := repository_mocks.NewMockUserRepository(ctrl)
 			tt.mockSetup(repo)
 
-			service := NewUserService(repo)
+			service := NewUserService(repo, password.NewPolicy(8, false), &password.BcryptHasher{Cost: bcrypt.DefaultCost})
 			err := service.Register(context.Background(), tt.login, tt.password)
 
-			if tt.expectedErr != nil && err.Error() != tt.expectedErr.Error() {
+			if tt.expectedErr != nil && (err == nil || (err.Error() != tt.expectedErr.Error() && !errors.Is(err, tt.expectedErr))) {
 				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
 			}
 			if tt.expectedErr == nil && err != nil {
@@ -110,7 +125,7 @@ func TestUserService_Authenticate(t *testing.T) {
 			repo := repository_mocks.NewMockUserRepository(ctrl)
 			repo.EXPECT().GetUserByLogin(gomock.Any(), tt.login).Return(tt.mockUser, tt.mockErr)
 
-			service := NewUserService(repo)
+			service := NewUserService(repo, password.NewPolicy(8, false), &password.BcryptHasher{Cost: bcrypt.DefaultCost})
 			err := service.Authenticate(context.Background(), tt.login, tt.password)
 
 			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
@@ -123,6 +138,52 @@ func TestUserService_Authenticate(t *testing.T) {
 	}
 }
 
+func TestUserService_AuthenticateRehash(t *testing.T) {
+	legacy, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+
+	hasher, err := password.NewHasher(password.HasherConfig{
+		Algorithm: password.AlgorithmArgon2id,
+		Argon2: password.Argon2Params{
+			Memory:      1024,
+			Iterations:  1,
+			Parallelism: 1,
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+		BcryptCost: bcrypt.MinCost,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository_mocks.NewMockUserRepository(ctrl)
+	repo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&models.User{ID: 1, Login: "user1", Password: string(legacy)}, nil)
+
+	var rehashed string
+	repo.EXPECT().UpdatePasswordHash(gomock.Any(), int64(1), string(legacy), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, _, hash string) (bool, error) {
+			rehashed = hash
+			return true, nil
+		},
+	)
+
+	service := NewUserService(repo, password.NewPolicy(8, false), hasher)
+	if err := service.Authenticate(context.Background(), "user1", "password123"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ok, err := hasher.Verify("password123", rehashed)
+	if err != nil || !ok {
+		t.Errorf("rehashed password does not verify: %v", err)
+	}
+	if hasher.NeedsRehash(rehashed) {
+		t.Error("rehashed password should use current algorithm")
+	}
+}
+
 func TestUserService_GetUserByLogin(t *testing.T) {
 	expectedUser := &models.User{Login: "user1", Password: "hashed"}
 
@@ -132,7 +193,7 @@ func TestUserService_GetUserByLogin(t *testing.T) {
 	repo := repository_mocks.NewMockUserRepository(ctrl)
 	repo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(expectedUser, nil)
 
-	service := NewUserService(repo)
+	service := NewUserService(repo, password.NewPolicy(8, false), &password.BcryptHasher{Cost: bcrypt.DefaultCost})
 
 	user, err := service.GetUserByLogin(context.Background(), "user1")
 	if err != nil {
@@ -142,3 +203,99 @@ func TestUserService_GetUserByLogin(t *testing.T) {
 		t.Errorf("expected login %s, got %s", expectedUser.Login, user.Login)
 	}
 }
+
+func TestUserService_ChangePassword(t *testing.T) {
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+
+	tests := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+		mockSetup       func(m *repository_mocks.MockUserRepository)
+		expectedErr     error
+	}{
+		{
+			name:            "успешная смена пароля",
+			currentPassword: "password123",
+			newPassword:     "new-strong-secret",
+			mockSetup: func(m *repository_mocks.MockUserRepository) {
+				m.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(&models.User{ID: 1, Login: "user1", Password: string(hashed)}, nil)
+				m.EXPECT().UpdatePassword(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:            "неверный текущий пароль",
+			currentPassword: "wrongpass",
+			newPassword:     "new-strong-secret",
+			mockSetup: func(m *repository_mocks.MockUserRepository) {
+				m.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(&models.User{ID: 1, Login: "user1", Password: string(hashed)}, nil)
+			},
+			expectedErr: apperrors.ErrInvalidCredentials,
+		},
+		{
+			name:            "новый пароль совпадает с текущим",
+			currentPassword: "password123",
+			newPassword:     "password123",
+			mockSetup: func(m *repository_mocks.MockUserRepository) {
+				m.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(&models.User{ID: 1, Login: "user1", Password: string(hashed)}, nil)
+			},
+			expectedErr: apperrors.ErrSamePassword,
+		},
+		{
+			name:            "слабый новый пароль",
+			currentPassword: "password123",
+			newPassword:     "short",
+			mockSetup: func(m *repository_mocks.MockUserRepository) {
+				m.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(&models.User{ID: 1, Login: "user1", Password: string(hashed)}, nil)
+			},
+			expectedErr: apperrors.ErrWeakPassword,
+		},
+		{
+			name:            "пользователь не найден",
+			currentPassword: "password123",
+			newPassword:     "new-strong-secret",
+			mockSetup: func(m *repository_mocks.MockUserRepository) {
+				m.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(nil, apperrors.ErrUserNotFound)
+			},
+			expectedErr: apperrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repository_mocks.NewMockUserRepository(ctrl)
+			tt.mockSetup(repo)
+
+			service := NewUserService(repo, password.NewPolicy(8, true), &password.BcryptHasher{Cost: bcrypt.DefaultCost})
+			err := service.ChangePassword(context.Background(), 1, tt.currentPassword, tt.newPassword)
+
+			if tt.expectedErr != nil && !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr == nil && err != nil {
+				t.Errorf("expected nil error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUserService_GetTokenVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository_mocks.NewMockUserRepository(ctrl)
+	repo.EXPECT().GetUserByID(gomock.Any(), int64(1)).Return(&models.User{ID: 1, TokenVersion: 3}, nil)
+
+	service := NewUserService(repo, password.NewPolicy(8, false), &password.BcryptHasher{Cost: bcrypt.DefaultCost})
+
+	version, err := service.GetTokenVersion(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version != 3 {
+		t.Errorf("expected token version 3, got %d", version)
+	}
+}
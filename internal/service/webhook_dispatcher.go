@@ -0,0 +1,231 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/repository"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	WebhookSignatureHeader = "X-Gophermart-Signature"
+	WebhookEventHeader     = "X-Gophermart-Event"
+	WebhookDeliveryHeader  = "X-Gophermart-Delivery"
+
+	DefaultWebhookMaxAttempts = 8
+
+	webhookBatchSize    = 50
+	webhookBaseBackoff  = 30 * time.Second
+	webhookMaxBackoff   = time.Hour
+	webhookMaxErrorBody = 512
+)
+
+// WebhookDispatcher drains the webhook_deliveries outbox. Each due delivery is
+// POSTed once per attempt; failures are rescheduled with exponential back-off
+// until maxAttempts is reached, after which the delivery is marked dead.
+type WebhookDispatcher struct {
+	repo         repository.WebhookRepository
+	client       *http.Client
+	maxAttempts  int
+	pollInterval time.Duration
+	now          func() time.Time
+}
+
+// NewWebhookDispatcher builds a dispatcher whose HTTP client refuses to
+// connect to private addresses unless allowPrivateNetworks is set.
+func NewWebhookDispatcher(repo repository.WebhookRepository, timeout time.Duration, maxAttempts int, interval time.Duration, allowPrivateNetworks bool) *WebhookDispatcher {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultWebhookMaxAttempts
+	}
+	return &WebhookDispatcher{
+		repo:         repo,
+		client:       newWebhookClient(timeout, allowPrivateNetworks),
+		maxAttempts:  maxAttempts,
+		pollInterval: interval,
+		now:          time.Now,
+	}
+}
+
+var errPrivateAddress = errors.New("private addresses are not allowed")
+
+// newWebhookClient returns the client used for deliveries. The address is
+// checked when the connection is made, after DNS resolution, so a hostname
+// resolving to an internal address is refused as well. Redirects are not
+// followed and proxies are not used, since either would bypass the check.
+func newWebhookClient(timeout time.Duration, allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if !allowPrivateNetworks {
+		dialer.Control = denyPrivateAddress
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func denyPrivateAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if addr, err := netip.ParseAddr(host); err != nil || isPrivateAddress(addr) {
+		return fmt.Errorf("%w: %s", errPrivateAddress, host)
+	}
+	return nil
+}
+
+// privatePrefixes lists the ranges a webhook must not reach: loopback,
+// private, shared and link-local networks, multicast and reserved space,
+// and the IPv6 forms that embed an IPv4 address and could be routed to one.
+var privatePrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("::ffff:0:0/96"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// isPrivateAddress reports whether addr falls into privatePrefixes. IPv4-mapped
+// IPv6 addresses are unmapped first so ::ffff:127.0.0.1 counts as loopback.
+func isPrivateAddress(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	for _, prefix := range privatePrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.dispatchDue(ctx)
+		}
+	}
+}
+
+func (d *WebhookDispatcher) dispatchDue(ctx context.Context) {
+	// The lease must outlive a full round of HTTP timeouts, otherwise another
+	// instance could pick the same deliveries up while they are in flight.
+	lease := d.client.Timeout*webhookBatchSize + time.Minute
+
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, webhookBatchSize, lease)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to claim webhook deliveries", zap.Error(err))
+		return
+	}
+
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+		d.deliver(ctx, delivery)
+	}
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery models.WebhookDelivery) {
+	statusCode, sendErr := d.send(ctx, delivery)
+
+	var err error
+	attempt := delivery.Attempts + 1
+	switch {
+	case sendErr == nil:
+		err = d.repo.MarkDeliveryDelivered(ctx, delivery.ID, statusCode)
+	case attempt >= d.maxAttempts:
+		logger.FromContext(ctx).Warn("webhook delivery exhausted retries",
+			zap.Int64("delivery", delivery.ID), zap.Int("attempts", attempt), zap.Error(sendErr))
+		err = d.repo.MarkDeliveryDead(ctx, delivery.ID, statusCode, sendErr.Error())
+	default:
+		err = d.repo.RescheduleDelivery(ctx, delivery.ID, statusCode, sendErr.Error(), d.now().Add(exponentialBackoff(attempt, webhookBaseBackoff, webhookMaxBackoff)))
+	}
+
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to record webhook delivery result", zap.Int64("delivery", delivery.ID), zap.Error(err))
+	}
+}
+
+func (d *WebhookDispatcher) send(ctx context.Context, delivery models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.FromContext(ctx).Error("failed to close webhook response body", zap.Error(err))
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxErrorBody))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookMaxErrorBody))
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the signature header value "t=<unix>,v1=<hex>",
+// where v1 is HMAC-SHA256 over "<unix>.<payload>" keyed by the webhook secret.
+// Receivers should recompute it and reject stale timestamps.
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
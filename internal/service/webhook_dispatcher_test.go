@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/a2sh3r/gophermart/internal/logger"
+	"github.com/a2sh3r/gophermart/internal/mocks/repository_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestWebhookDispatcher_dispatchDue(t *testing.T) {
+	logger.Log = zap.NewNop()
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	payload := []byte(`{"type":"order.processed","data":{"number":"12345678903"}}`)
+
+	tests := []struct {
+		name       string
+		status     int
+		attempts   int
+		maxAttempt int
+		expect     func(m *repository_mocks.MockWebhookRepository)
+	}{
+		{
+			name:       "успешная доставка",
+			status:     http.StatusNoContent,
+			maxAttempt: 3,
+			expect: func(m *repository_mocks.MockWebhookRepository) {
+				m.EXPECT().MarkDeliveryDelivered(gomock.Any(), int64(7), http.StatusNoContent).Return(nil)
+			},
+		},
+		{
+			name:       "ошибка получателя — повтор с back-off",
+			status:     http.StatusInternalServerError,
+			attempts:   1,
+			maxAttempt: 3,
+			expect: func(m *repository_mocks.MockWebhookRepository) {
+				m.EXPECT().RescheduleDelivery(gomock.Any(), int64(7), http.StatusInternalServerError, gomock.Any(), now.Add(time.Minute)).Return(nil)
+			},
+		},
+		{
+			name:       "исчерпаны попытки — dead letter",
+			status:     http.StatusBadGateway,
+			attempts:   2,
+			maxAttempt: 3,
+			expect: func(m *repository_mocks.MockWebhookRepository) {
+				m.EXPECT().MarkDeliveryDead(gomock.Any(), int64(7), http.StatusBadGateway, gomock.Any()).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var received atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received.Add(1)
+				body, _ := io.ReadAll(r.Body)
+
+				assert.Equal(t, payload, body)
+				assert.Equal(t, models.WebhookEventOrderProcessed, r.Header.Get(WebhookEventHeader))
+				assert.Equal(t, "7", r.Header.Get(WebhookDeliveryHeader))
+				assert.Equal(t, SignWebhookPayload("whsec_test", now.Unix(), body), r.Header.Get(WebhookSignatureHeader))
+
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			repo := repository_mocks.NewMockWebhookRepository(ctrl)
+			repo.EXPECT().ClaimDueDeliveries(gomock.Any(), webhookBatchSize, gomock.Any()).Return([]models.WebhookDelivery{{
+				ID:        7,
+				EventType: models.WebhookEventOrderProcessed,
+				Payload:   payload,
+				Attempts:  tt.attempts,
+				URL:       server.URL,
+				Secret:    "whsec_test",
+			}}, nil)
+			tt.expect(repo)
+
+			dispatcher := NewWebhookDispatcher(repo, time.Second, tt.maxAttempt, time.Second, true)
+			dispatcher.now = func() time.Time { return now }
+			dispatcher.dispatchDue(context.Background())
+
+			assert.Equal(t, int32(1), received.Load())
+		})
+	}
+}
+
+func TestNewWebhookClient(t *testing.T) {
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/internal", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	t.Run("адреса внутренней сети отклоняются после резолва", func(t *testing.T) {
+		client := newWebhookClient(time.Second, false)
+		for _, target := range []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)} {
+			resp, err := client.Post(target, "application/json", nil)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			assert.ErrorIs(t, err, errPrivateAddress, target)
+		}
+		assert.Equal(t, int32(0), received.Load())
+	})
+
+	t.Run("редиректы не выполняются", func(t *testing.T) {
+		client := newWebhookClient(time.Second, true)
+		resp, err := client.Post(server.URL+"/redirect", "application/json", nil)
+		if assert.NoError(t, err) {
+			_ = resp.Body.Close()
+			assert.Equal(t, http.StatusFound, resp.StatusCode)
+		}
+		assert.Equal(t, int32(1), received.Load())
+	})
+}
+
+func TestIsPrivateAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		private bool
+	}{
+		{name: "публичный IPv4", addr: "93.184.216.34", private: false},
+		{name: "публичный IPv6", addr: "2606:4700::6810:84e5", private: false},
+		{name: "loopback", addr: "127.0.0.1", private: true},
+		{name: "частная сеть", addr: "10.1.2.3", private: true},
+		{name: "this network", addr: "0.1.2.3", private: true},
+		{name: "неуказанный адрес", addr: "0.0.0.0", private: true},
+		{name: "CGNAT", addr: "100.64.0.1", private: true},
+		{name: "граница CGNAT", addr: "100.128.0.1", private: false},
+		{name: "link-local", addr: "169.254.169.254", private: true},
+		{name: "multicast", addr: "224.0.0.1", private: true},
+		{name: "broadcast", addr: "255.255.255.255", private: true},
+		{name: "IPv6 loopback", addr: "::1", private: true},
+		{name: "IPv4-mapped loopback", addr: "::ffff:127.0.0.1", private: true},
+		{name: "IPv4-mapped публичный", addr: "::ffff:93.184.216.34", private: false},
+		{name: "NAT64", addr: "64:ff9b::7f00:1", private: true},
+		{name: "6to4", addr: "2002:7f00:1::1", private: true},
+		{name: "ULA", addr: "fd00::1", private: true},
+		{name: "IPv6 link-local с зоной", addr: "fe80::1%eth0", private: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.private, isPrivateAddress(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, exponentialBackoff(1, webhookBaseBackoff, webhookMaxBackoff))
+	assert.Equal(t, time.Minute, exponentialBackoff(2, webhookBaseBackoff, webhookMaxBackoff))
+	assert.Equal(t, 4*time.Minute, exponentialBackoff(4, webhookBaseBackoff, webhookMaxBackoff))
+	assert.Equal(t, time.Hour, exponentialBackoff(20, webhookBaseBackoff, webhookMaxBackoff))
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	sig := SignWebhookPayload("secret", 1700000000, []byte(`{}`))
+	assert.Equal(t, sig, SignWebhookPayload("secret", 1700000000, []byte(`{}`)))
+	assert.NotEqual(t, sig, SignWebhookPayload("other", 1700000000, []byte(`{}`)))
+	assert.Contains(t, sig, "t=1700000000,v1=")
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/a2sh3r/gophermart/internal/repository"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+const (
+	webhookSecretPrefix    = "whsec_"
+	webhookSecretLen       = 24
+	webhookMaxURLLength    = 2048
+	webhookDeliveriesLimit = 100
+)
+
+type WebhookService interface {
+	Create(ctx context.Context, userID int64, req models.CreateWebhookRequest) (*models.CreateWebhookResponse, error)
+	List(ctx context.Context, userID int64) ([]models.Webhook, error)
+	Delete(ctx context.Context, userID, webhookID int64) error
+	Deliveries(ctx context.Context, userID, webhookID int64) ([]models.WebhookDelivery, error)
+	HandleDomainEvent(ctx context.Context, event models.DomainEvent) error
+}
+
+type webhookService struct {
+	repo                 repository.WebhookRepository
+	allowPrivateNetworks bool
+}
+
+func NewWebhookService(repo repository.WebhookRepository, allowPrivateNetworks bool) WebhookService {
+	return &webhookService{repo: repo, allowPrivateNetworks: allowPrivateNetworks}
+}
+
+func (s *webhookService) Create(ctx context.Context, userID int64, req models.CreateWebhookRequest) (*models.CreateWebhookResponse, error) {
+	target, err := s.validateURL(req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	eventTypes, err := normalizeWebhookEvents(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, webhookSecretLen)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	webhook := &models.Webhook{
+		UserID:     userID,
+		URL:        target,
+		Secret:     webhookSecretPrefix + hex.EncodeToString(buf),
+		EventTypes: eventTypes,
+	}
+
+	if err := s.repo.CreateWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	return &models.CreateWebhookResponse{Webhook: *webhook, Secret: webhook.Secret}, nil
+}
+
+func (s *webhookService) List(ctx context.Context, userID int64) ([]models.Webhook, error) {
+	return s.repo.GetWebhooksByUser(ctx, userID)
+}
+
+func (s *webhookService) Delete(ctx context.Context, userID, webhookID int64) error {
+	return s.repo.DeleteWebhook(ctx, userID, webhookID)
+}
+
+func (s *webhookService) Deliveries(ctx context.Context, userID, webhookID int64) ([]models.WebhookDelivery, error) {
+	return s.repo.GetWebhookDeliveries(ctx, userID, webhookID, webhookDeliveriesLimit)
+}
+
+// HandleDomainEvent is the event bus subscriber that turns domain events into
+// webhook deliveries for the user's matching endpoints.
+func (s *webhookService) HandleDomainEvent(ctx context.Context, event models.DomainEvent) error {
+	var eventType string
+	switch event.Type {
+	case models.EventOrderStatusChanged:
+		var payload models.OrderStatusChangedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+		if payload.Status != StatusProcessed {
+			return nil
+		}
+		eventType = models.WebhookEventOrderProcessed
+	case models.EventWithdrawalMade:
+		eventType = models.WebhookEventBalanceWithdrawn
+	default:
+		return nil
+	}
+
+	body, err := json.Marshal(models.WebhookEvent{
+		Type:      eventType,
+		CreatedAt: event.OccurredAt.UTC(),
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.repo.EnqueueDeliveries(ctx, event.UserID, eventType, event.ID, body)
+}
+
+// validateURL only accepts absolute http(s) URLs. Unless private networks are
+// explicitly allowed, localhost and literal private addresses are rejected
+// early to give the user a clear error. This is not the SSRF guard: hostnames
+// are resolved only at delivery time, where the dispatcher's client refuses
+// private addresses.
+func (s *webhookService) validateURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || len(raw) > webhookMaxURLLength {
+		return "", apperrors.ErrInvalidWebhookURL
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return "", apperrors.ErrInvalidWebhookURL
+	}
+
+	if !s.allowPrivateNetworks {
+		host := u.Hostname()
+		if strings.EqualFold(host, "localhost") {
+			return "", fmt.Errorf("%w: private addresses are not allowed", apperrors.ErrInvalidWebhookURL)
+		}
+		if addr, err := netip.ParseAddr(host); err == nil && isPrivateAddress(addr) {
+			return "", fmt.Errorf("%w: private addresses are not allowed", apperrors.ErrInvalidWebhookURL)
+		}
+	}
+
+	return u.String(), nil
+}
+
+func normalizeWebhookEvents(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return append([]string(nil), models.WebhookEventTypes...), nil
+	}
+
+	var result []string
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if !slices.Contains(models.WebhookEventTypes, eventType) {
+			return nil, fmt.Errorf("%w: %q", apperrors.ErrInvalidWebhookEvent, eventType)
+		}
+		if !slices.Contains(result, eventType) {
+			result = append(result, eventType)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/a2sh3r/gophermart/internal/apperrors"
+	"github.com/a2sh3r/gophermart/internal/mocks/repository_mocks"
+	"github.com/a2sh3r/gophermart/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookService_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		req            models.CreateWebhookRequest
+		allowPrivate   bool
+		mockSetup      func(m *repository_mocks.MockWebhookRepository)
+		expectedErr    error
+		expectedEvents []string
+	}{
+		{
+			name: "успешное создание со всеми событиями по умолчанию",
+			req:  models.CreateWebhookRequest{URL: "https://crm.example.com/hooks"},
+			mockSetup: func(m *repository_mocks.MockWebhookRepository) {
+				m.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedEvents: models.WebhookEventTypes,
+		},
+		{
+			name: "дубликаты событий схлопываются",
+			req: models.CreateWebhookRequest{
+				URL:        "https://crm.example.com/hooks",
+				EventTypes: []string{models.WebhookEventOrderProcessed, models.WebhookEventOrderProcessed},
+			},
+			mockSetup: func(m *repository_mocks.MockWebhookRepository) {
+				m.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedEvents: []string{models.WebhookEventOrderProcessed},
+		},
+		{
+			name:        "неизвестное событие",
+			req:         models.CreateWebhookRequest{URL: "https://crm.example.com/hooks", EventTypes: []string{"user.deleted"}},
+			mockSetup:   func(m *repository_mocks.MockWebhookRepository) {},
+			expectedErr: apperrors.ErrInvalidWebhookEvent,
+		},
+		{
+			name:        "неподдерживаемая схема",
+			req:         models.CreateWebhookRequest{URL: "ftp://crm.example.com/hooks"},
+			mockSetup:   func(m *repository_mocks.MockWebhookRepository) {},
+			expectedErr: apperrors.ErrInvalidWebhookURL,
+		},
+		{
+			name:        "приватный адрес запрещён",
+			req:         models.CreateWebhookRequest{URL: "http://10.0.0.5/hooks"},
+			mockSetup:   func(m *repository_mocks.MockWebhookRepository) {},
+			expectedErr: apperrors.ErrInvalidWebhookURL,
+		},
+		{
+			name:         "приватный адрес разрешён настройкой",
+			req:          models.CreateWebhookRequest{URL: "http://127.0.0.1:9000/hooks"},
+			allowPrivate: true,
+			mockSetup: func(m *repository_mocks.MockWebhookRepository) {
+				m.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedEvents: models.WebhookEventTypes,
+		},
+		{
+			name: "ошибка репозитория",
+			req:  models.CreateWebhookRequest{URL: "https://crm.example.com/hooks"},
+			mockSetup: func(m *repository_mocks.MockWebhookRepository) {
+				m.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+			},
+			expectedErr: errors.New("db error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repository_mocks.NewMockWebhookRepository(ctrl)
+			tt.mockSetup(repo)
+
+			service := NewWebhookService(repo, tt.allowPrivate)
+			resp, err := service.Create(context.Background(), 1, tt.req)
+
+			if tt.expectedErr != nil {
+				assert.Error(t, err)
+				if !errors.Is(err, tt.expectedErr) && err.Error() != tt.expectedErr.Error() {
+					t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEvents, resp.EventTypes)
+			assert.True(t, strings.HasPrefix(resp.Secret, webhookSecretPrefix))
+			assert.Equal(t, int64(1), resp.UserID)
+		})
+	}
+}
+
+func TestWebhookService_HandleDomainEvent(t *testing.T) {
+	tests := []struct {
+		name      string
+		event     models.DomainEvent
+		mockSetup func(m *repository_mocks.MockWebhookRepository)
+	}{
+		{
+			name: "заказ обработан",
+			event: models.DomainEvent{ID: 5, Type: models.EventOrderStatusChanged, UserID: 1,
+				Payload: []byte(`{"number":"12345678903","previous_status":"PROCESSING","status":"PROCESSED","accrual":100}`)},
+			mockSetup: func(m *repository_mocks.MockWebhookRepository) {
+				m.EXPECT().EnqueueDeliveries(gomock.Any(), int64(1), models.WebhookEventOrderProcessed, int64(5), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ int64, _ string, _ int64, body []byte) error {
+						assert.Contains(t, string(body), `"type":"order.processed"`)
+						assert.Contains(t, string(body), `"number":"12345678903"`)
+						return nil
+					})
+			},
+		},
+		{
+			name: "промежуточный статус игнорируется",
+			event: models.DomainEvent{ID: 6, Type: models.EventOrderStatusChanged, UserID: 1,
+				Payload: []byte(`{"number":"12345678903","previous_status":"NEW","status":"PROCESSING"}`)},
+			mockSetup: func(m *repository_mocks.MockWebhookRepository) {},
+		},
+		{
+			name:  "вывод средств",
+			event: models.DomainEvent{ID: 7, Type: models.EventWithdrawalMade, UserID: 2, Payload: []byte(`{"order":"79927398713","sum":10}`)},
+			mockSetup: func(m *repository_mocks.MockWebhookRepository) {
+				m.EXPECT().EnqueueDeliveries(gomock.Any(), int64(2), models.WebhookEventBalanceWithdrawn, int64(7), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:      "неподписанный тип события",
+			event:     models.DomainEvent{ID: 8, Type: models.EventOrderUploaded, UserID: 1, Payload: []byte(`{}`)},
+			mockSetup: func(m *repository_mocks.MockWebhookRepository) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repository_mocks.NewMockWebhookRepository(ctrl)
+			tt.mockSetup(repo)
+
+			service := NewWebhookService(repo, false)
+			assert.NoError(t, service.HandleDomainEvent(context.Background(), tt.event))
+		})
+	}
+}